@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"github.com/traPtitech/DevOpsBot/pkg/config"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect the config",
+}
+
+var configPrintCmd = &cobra.Command{
+	Use:          "print",
+	Short:        "Print the loaded config with included files merged, showing secret references instead of the resolved values",
+	SilenceUsage: true, // Do not display command usage when RunE returns error
+	RunE: func(cmd *cobra.Command, args []string) error {
+		redacted, err := config.C.Redacted()
+		if err != nil {
+			return err
+		}
+		b, err := yaml.Marshal(redacted)
+		if err != nil {
+			return fmt.Errorf("marshalling config: %w", err)
+		}
+		_, err = cmd.OutOrStdout().Write(b)
+		return err
+	},
+}
+
+func init() {
+	configCmd.AddCommand(configPrintCmd)
+	configCmd.AddCommand(configSchemaCmd)
+}
@@ -0,0 +1,23 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/traPtitech/DevOpsBot/pkg/config"
+)
+
+var configSchemaCmd = &cobra.Command{
+	Use:          "schema",
+	Short:        "Print JSON Schema of the config file, for editor completion and validation",
+	SilenceUsage: true, // Do not display command usage when RunE returns error
+	// Does not need to load the config
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error { return nil },
+	RunE: func(cmd *cobra.Command, args []string) error {
+		schema, err := config.Schema()
+		if err != nil {
+			return err
+		}
+		_, err = cmd.OutOrStdout().Write(schema)
+		return err
+	},
+}
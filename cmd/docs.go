@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/traPtitech/DevOpsBot/pkg/bot"
+	"github.com/traPtitech/DevOpsBot/pkg/config"
+)
+
+var docsFormat string
+
+var docsCmd = &cobra.Command{
+	Use:          "docs",
+	Short:        "Print documentation of the configured commands and aliases in Markdown, HTML or JSON",
+	SilenceUsage: true, // Do not display command usage when RunE returns error
+	RunE: func(cmd *cobra.Command, args []string) error {
+		root, err := bot.Compile()
+		if err != nil {
+			return err
+		}
+		defer root.Cleanup()
+		docs := root.Docs(config.C.Prefix)
+
+		var out []byte
+		switch docsFormat {
+		case "markdown", "md":
+			out = docs.Markdown()
+		case "html":
+			out = docs.HTML()
+		case "json":
+			out, err = docs.JSON()
+			if err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("unknown format %s, expected one of markdown, html, json", docsFormat)
+		}
+		_, err = cmd.OutOrStdout().Write(out)
+		return err
+	},
+}
+
+func init() {
+	docsCmd.Flags().StringVar(&docsFormat, "format", "markdown", "output format (markdown, html, json)")
+}
@@ -0,0 +1,28 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/traPtitech/DevOpsBot/pkg/bot"
+	"github.com/traPtitech/DevOpsBot/pkg/config"
+)
+
+var replExecutor string
+
+var replCmd = &cobra.Command{
+	Use:          "repl",
+	Short:        "Execute commands typed into stdin locally, without connecting to any chat platform",
+	SilenceUsage: true, // Do not display command usage when RunE returns error
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return bot.Run(cmd.Context(), func(c *config.Config) {
+			c.Mode = []string{"cli"}
+			if replExecutor != "" {
+				c.CLI.Executor = replExecutor
+			}
+		})
+	},
+}
+
+func init() {
+	replCmd.Flags().StringVar(&replExecutor, "executor", "", "user ID to execute commands as (overrides cli.executor config)")
+}
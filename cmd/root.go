@@ -1,12 +1,16 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/spf13/cobra"
 
 	"github.com/traPtitech/DevOpsBot/pkg/bot"
+	"github.com/traPtitech/DevOpsBot/pkg/config"
 	"github.com/traPtitech/DevOpsBot/pkg/utils"
 )
 
@@ -15,18 +19,25 @@ var rootCmd = &cobra.Command{
 	Use:          "DevOpsBot",
 	Short:        "A ChatOps bot for executing arbitrary shell commands.",
 	SilenceUsage: true,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		return config.Load()
+	},
 	PreRun: func(cmd *cobra.Command, args []string) {
 		fmt.Printf("DevOpsBot v%s initializing\n", utils.Version())
 	},
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return bot.Run(cmd.Context())
+		return bot.Run(cmd.Context(), nil)
 	},
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
 func Execute() {
-	err := rootCmd.Execute()
+	// Shut down gracefully on signals
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	err := rootCmd.ExecuteContext(ctx)
 	if err != nil {
 		os.Exit(1)
 	}
@@ -34,6 +45,10 @@ func Execute() {
 
 func init() {
 	rootCmd.AddCommand(serverCmd)
+	rootCmd.AddCommand(replCmd)
+	rootCmd.AddCommand(slackManifestCmd)
+	rootCmd.AddCommand(docsCmd)
+	rootCmd.AddCommand(configCmd)
 
 	// Here you will define your flags and configuration settings.
 	// Cobra supports persistent flags, which, if defined here,
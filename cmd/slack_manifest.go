@@ -0,0 +1,27 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/traPtitech/DevOpsBot/pkg/bot"
+	"github.com/traPtitech/DevOpsBot/pkg/bot/slack"
+)
+
+var slackManifestCmd = &cobra.Command{
+	Use:          "slack-manifest",
+	Short:        "Print Slack app manifest snippet registering slash commands for the configured commands",
+	SilenceUsage: true, // Do not display command usage when RunE returns error
+	RunE: func(cmd *cobra.Command, args []string) error {
+		root, err := bot.Compile()
+		if err != nil {
+			return err
+		}
+		defer root.Cleanup()
+		manifest, err := slack.Manifest(root.ListAll())
+		if err != nil {
+			return err
+		}
+		_, err = cmd.OutOrStdout().Write(manifest)
+		return err
+	},
+}
@@ -0,0 +1,165 @@
+package bot
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/kballard/go-shellquote"
+	"github.com/samber/lo"
+
+	"github.com/traPtitech/DevOpsBot/pkg/config"
+	"github.com/traPtitech/DevOpsBot/pkg/domain"
+)
+
+// placeholderRegexp matches "{name}" and "{name=default}" placeholders in alias command lines.
+var placeholderRegexp = regexp.MustCompile(`\{([A-Za-z0-9_-]+)(?:=([^{}]*))?\}`)
+
+// alias is a compiled alias, expanded to the command line before dispatch.
+type alias struct {
+	name        string
+	description string
+	command     string
+	// tokens are the command line tokens, which may contain placeholders
+	tokens []string
+	// params are the placeholders in order of appearance
+	params   []*domain.Arg
+	defaults map[string]string
+}
+
+func compileAliases(acs []*config.AliasConfig, cmds map[string]domain.Command) (map[string]*alias, error) {
+	aliases := make(map[string]*alias, len(acs))
+	for _, ac := range acs {
+		if ac.Name == "" {
+			return nil, fmt.Errorf("alias needs a name")
+		}
+		if _, ok := cmds[ac.Name]; ok {
+			return nil, fmt.Errorf("alias %s conflicts with command", ac.Name)
+		}
+		if _, ok := aliases[ac.Name]; ok {
+			return nil, fmt.Errorf("alias %s conflict", ac.Name)
+		}
+		a, err := compileAlias(ac, cmds)
+		if err != nil {
+			return nil, fmt.Errorf("alias %s: %w", ac.Name, err)
+		}
+		aliases[ac.Name] = a
+	}
+	return aliases, nil
+}
+
+func compileAlias(ac *config.AliasConfig, cmds map[string]domain.Command) (*alias, error) {
+	tokens, err := shellquote.Split(ac.Command)
+	if err != nil {
+		return nil, fmt.Errorf("parsing command: %w", err)
+	}
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("command needs to be set")
+	}
+	if _, ok := cmds[tokens[0]]; !ok || placeholderRegexp.MatchString(tokens[0]) {
+		return nil, fmt.Errorf("unknown command %s", tokens[0])
+	}
+
+	a := &alias{
+		name:        ac.Name,
+		description: ac.Description,
+		command:     ac.Command,
+		tokens:      tokens,
+		defaults:    make(map[string]string),
+	}
+	seen := make(map[string]bool)
+	for i, token := range tokens[1:] {
+		for _, m := range placeholderRegexp.FindAllStringSubmatch(token, -1) {
+			name, hasDefault := m[1], strings.Contains(m[0], "=")
+			if seen[name] {
+				return nil, fmt.Errorf("placeholder %s appears more than once", name)
+			}
+			if hasDefault && m[2] == "" && m[0] == token && isFlag(tokens[i]) {
+				// The omitted placeholder would leave the flag without a value
+				return nil, fmt.Errorf("placeholder %s with empty default cannot follow flag %s, use %s={%s=} instead", name, tokens[i], tokens[i], name)
+			}
+			seen[name] = true
+			if !hasDefault && len(a.params) > 0 && !a.params[len(a.params)-1].Required {
+				return nil, fmt.Errorf("required placeholder %s cannot come after optional placeholders", name)
+			}
+			a.params = append(a.params, &domain.Arg{Name: name, Required: !hasDefault})
+			if hasDefault {
+				a.defaults[name] = m[2]
+			}
+		}
+	}
+	return a, nil
+}
+
+// isFlag reports whether the token is a flag taking the value from the next token. (example: "-n", "--env")
+func isFlag(token string) bool {
+	return len(token) > 1 && token[0] == '-' && !strings.Contains(token, "=")
+}
+
+// target returns the top-level command name which the alias expands to.
+func (a *alias) target() string {
+	return a.tokens[0]
+}
+
+// syntax returns the display syntax of the alias. (example: "restart <app> [ns]")
+func (a *alias) syntax(prefix string) string {
+	syntax := prefix + a.name
+	if len(a.params) > 0 {
+		syntax += " " + domain.ArgsSyntax(a.params)
+	}
+	return syntax
+}
+
+// expand fills the placeholders with the arguments, and returns the expanded command line.
+func (a *alias) expand(args []string) ([]string, error) {
+	if required := domain.RequiredArgs(a.params); len(args) < required {
+		return nil, fmt.Errorf("missing required argument `%s`", a.params[len(args)].Name)
+	}
+	values := make(map[string]string, len(a.params))
+	for i, p := range a.params {
+		if i < len(args) {
+			values[p.Name] = args[i]
+		} else {
+			values[p.Name] = a.defaults[p.Name]
+		}
+	}
+
+	expanded := make([]string, 0, len(a.tokens))
+	for i, token := range a.tokens {
+		replaced := placeholderRegexp.ReplaceAllStringFunc(token, func(placeholder string) string {
+			return values[placeholderRegexp.FindStringSubmatch(placeholder)[1]]
+		})
+		if replaced == "" && token != "" && !isFlag(a.tokens[max(i-1, 0)]) {
+			continue // Omit the argument if the placeholder is empty, unless it is the value of the flag
+		}
+		expanded = append(expanded, replaced)
+	}
+	if len(args) > len(a.params) {
+		expanded = append(expanded, args[len(a.params):]...)
+	}
+	return expanded, nil
+}
+
+// helpItem returns the help of the alias, showing the expanded command line.
+func (a *alias) helpItem(prefix string) *domain.ListItem {
+	content := []domain.Inline{domain.Code(a.syntax(prefix)), domain.Text(" → "), domain.Code(prefix + a.command)}
+	if a.description != "" {
+		content = append(content, domain.Text(" - "+a.description))
+	}
+	return &domain.ListItem{Content: content}
+}
+
+// aliasHelpMessage returns the help of the aliases whose target is available in the channel, sorted by name.
+func (dc *RootCommand) aliasHelpMessage(ctx domain.Context) []*domain.ListItem {
+	var items []*domain.ListItem
+	names := lo.Keys(dc.aliases)
+	slices.Sort(names)
+	for _, name := range names {
+		a := dc.aliases[name]
+		if dc.isAvailable(ctx, a.target()) {
+			items = append(items, a.helpItem(ctx.Channel().Prefix))
+		}
+	}
+	return items
+}
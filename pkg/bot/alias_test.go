@@ -0,0 +1,349 @@
+package bot
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/traPtitech/DevOpsBot/pkg/config"
+	"github.com/traPtitech/DevOpsBot/pkg/domain"
+	"github.com/traPtitech/DevOpsBot/pkg/domain/domaintest"
+)
+
+// mustCompileAliases compiles the commands with the aliases.
+func mustCompileAliases(t *testing.T, commands []*config.CommandConfig, aliases []*config.AliasConfig) *RootCommand {
+	t.Helper()
+	setConfig(t, []*config.CommandTemplateConfig{echoTemplate}, commands)
+	config.C.Aliases = aliases
+	root, err := Compile()
+	if err != nil {
+		t.Fatalf("Compile() error = %v", err)
+	}
+	return root
+}
+
+func TestCompile_AliasErrors(t *testing.T) {
+	commands := []*config.CommandConfig{
+		{Name: "deploy", TemplateRef: "echo"},
+	}
+
+	tests := []struct {
+		name    string
+		aliases []*config.AliasConfig
+		wantErr string
+	}{
+		{
+			name:    "no name",
+			aliases: []*config.AliasConfig{{Command: "deploy"}},
+			wantErr: "alias needs a name",
+		},
+		{
+			name:    "conflicts with command",
+			aliases: []*config.AliasConfig{{Name: "deploy", Command: "deploy"}},
+			wantErr: "alias deploy conflicts with command",
+		},
+		{
+			name:    "conflicts with help",
+			aliases: []*config.AliasConfig{{Name: "help", Command: "deploy"}},
+			wantErr: "alias help conflicts with command",
+		},
+		{
+			name: "duplicate",
+			aliases: []*config.AliasConfig{
+				{Name: "d", Command: "deploy"},
+				{Name: "d", Command: "deploy"},
+			},
+			wantErr: "alias d conflict",
+		},
+		{
+			name:    "empty command",
+			aliases: []*config.AliasConfig{{Name: "d"}},
+			wantErr: "command needs to be set",
+		},
+		{
+			name:    "unknown command",
+			aliases: []*config.AliasConfig{{Name: "d", Command: "restart app"}},
+			wantErr: "unknown command restart",
+		},
+		{
+			name:    "alias chaining",
+			aliases: []*config.AliasConfig{{Name: "d", Command: "deploy"}, {Name: "dd", Command: "d"}},
+			wantErr: "unknown command d",
+		},
+		{
+			name:    "placeholder command",
+			aliases: []*config.AliasConfig{{Name: "d", Command: "{cmd}"}},
+			wantErr: "unknown command {cmd}",
+		},
+		{
+			name:    "duplicate placeholder",
+			aliases: []*config.AliasConfig{{Name: "d", Command: "deploy {app} {app}"}},
+			wantErr: "placeholder app appears more than once",
+		},
+		{
+			name:    "empty default after flag",
+			aliases: []*config.AliasConfig{{Name: "d", Command: "deploy -n {ns=}"}},
+			wantErr: "placeholder ns with empty default cannot follow flag -n",
+		},
+		{
+			name:    "required after optional",
+			aliases: []*config.AliasConfig{{Name: "d", Command: "deploy {env=stg} {app}"}},
+			wantErr: "required placeholder app cannot come after optional placeholders",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setConfig(t, []*config.CommandTemplateConfig{echoTemplate}, commands)
+			config.C.Aliases = tt.aliases
+			_, err := Compile()
+			if err == nil {
+				t.Fatalf("Compile() error = nil, want %q", tt.wantErr)
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Compile() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAliasExpand(t *testing.T) {
+	cmds := map[string]domain.Command{"deploy": nil}
+	a, err := compileAlias(&config.AliasConfig{Name: "d", Command: "deploy {app} -n {ns=default} --tag={tag=} {extra=}"}, cmds)
+	if err != nil {
+		t.Fatalf("compileAlias() error = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		args []string
+		want []string
+	}{
+		{"defaults", []string{"web"}, []string{"deploy", "web", "-n", "default", "--tag="}},
+		{"empty flag value is kept", []string{"web", ""}, []string{"deploy", "web", "-n", "", "--tag="}},
+		{"all placeholders", []string{"web", "prod", "v1", "x"}, []string{"deploy", "web", "-n", "prod", "--tag=v1", "x"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := a.expand(tt.args)
+			if err != nil {
+				t.Fatalf("expand() error = %v", err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("expand() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExecute_Alias(t *testing.T) {
+	root := mustCompileAliases(t,
+		[]*config.CommandConfig{
+			{
+				Name: "deploy",
+				SubCommands: []*config.CommandConfig{
+					{Name: "app", TemplateRef: "echo", AllowArgs: true, Operators: []string{"alice"}},
+				},
+			},
+			{Name: "status", TemplateRef: "echo", AllowArgs: true},
+		},
+		[]*config.AliasConfig{
+			{Name: "dp", Command: "deploy app {name} --env={env=stg} {tag=}"},
+			{Name: "st", Command: "status --verbose"},
+		},
+	)
+	channel := &domain.Channel{ID: "stg", Prefix: "!", Commands: []string{"deploy"}}
+
+	tests := []struct {
+		name      string
+		executor  string
+		channel   *domain.Channel
+		args      []string
+		wantKinds []domaintest.ReplyKind
+		wantLast  string
+	}{
+		{
+			name:      "defaults",
+			executor:  "alice",
+			args:      []string{"dp", "web"},
+			wantKinds: []domaintest.ReplyKind{domaintest.ReplyRunning, domaintest.ReplySuccess},
+			wantLast:  "web --env=stg\n",
+		},
+		{
+			name:      "all placeholders",
+			executor:  "alice",
+			args:      []string{"dp", "web", "prod", "v1"},
+			wantKinds: []domaintest.ReplyKind{domaintest.ReplyRunning, domaintest.ReplySuccess},
+			wantLast:  "web --env=prod v1",
+		},
+		{
+			name:      "extra args are appended",
+			executor:  "bob",
+			args:      []string{"st", "web", "db"},
+			wantKinds: []domaintest.ReplyKind{domaintest.ReplyRunning, domaintest.ReplySuccess},
+			wantLast:  "--verbose web db",
+		},
+		{
+			name:      "missing placeholder",
+			executor:  "alice",
+			args:      []string{"dp"},
+			wantKinds: []domaintest.ReplyKind{domaintest.ReplyBad},
+			wantLast:  "Invalid arguments for `/dp`: missing required argument `name`\n\nUsage: `/dp <name> [env] [tag]`",
+		},
+		{
+			name:      "operators of the target",
+			executor:  "bob",
+			args:      []string{"dp", "web"},
+			wantKinds: []domaintest.ReplyKind{domaintest.ReplyForbid},
+			wantLast:  "You do not have permission to execute this command (`/deploy app`).",
+		},
+		{
+			name:      "available channel of the target",
+			executor:  "alice",
+			channel:   channel,
+			args:      []string{"dp", "web"},
+			wantKinds: []domaintest.ReplyKind{domaintest.ReplyRunning, domaintest.ReplySuccess},
+		},
+		{
+			name:      "unavailable channel of the target",
+			executor:  "alice",
+			channel:   channel,
+			args:      []string{"st"},
+			wantKinds: []domaintest.ReplyKind{domaintest.ReplyBad},
+			wantLast:  "Unrecognized command `status`, try `!help`",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := domaintest.NewContext(tt.executor, tt.args...)
+			if tt.channel != nil {
+				ctx = ctx.WithChannel(tt.channel)
+			}
+			if err := root.Execute(ctx); err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+			assertReplies(t, ctx, tt.wantKinds, tt.wantLast)
+		})
+	}
+}
+
+func TestHelpCommand_Alias(t *testing.T) {
+	root := mustCompileAliases(t,
+		[]*config.CommandConfig{
+			{Name: "deploy", TemplateRef: "echo", AllowArgs: true, Description: "Deploy the app"},
+			{Name: "status", TemplateRef: "echo"},
+		},
+		[]*config.AliasConfig{
+			{Name: "dp", Command: "deploy {app} {env=stg}", Description: "Deploy shortcut"},
+			{Name: "st", Command: "status"},
+		},
+	)
+
+	tests := []struct {
+		name     string
+		channel  *domain.Channel
+		args     []string
+		wantKind domaintest.ReplyKind
+		want     []string
+		wantNot  []string
+	}{
+		{
+			name:     "root",
+			args:     []string{"help"},
+			wantKind: domaintest.ReplySuccess,
+			want: []string{
+				"## Aliases",
+				"- `/dp <app> [env]` → `/deploy {app} {env=stg}` - Deploy shortcut",
+				"- `/st` → `/status`",
+			},
+		},
+		{
+			name:     "root in channel",
+			channel:  &domain.Channel{ID: "stg", Prefix: "/", Commands: []string{"deploy"}},
+			args:     []string{"help"},
+			wantKind: domaintest.ReplySuccess,
+			want:     []string{"- `/dp <app> [env]`"},
+			wantNot:  []string{"`/st`"},
+		},
+		{
+			name:     "alias",
+			args:     []string{"help", "dp"},
+			wantKind: domaintest.ReplySuccess,
+			want: []string{
+				"## `/dp` Usage",
+				"- `/dp <app> [env]` → `/deploy {app} {env=stg}` - Deploy shortcut",
+				"- `/deploy` - Deploy the app (everyone)",
+			},
+		},
+		{
+			name:     "alias in unavailable channel",
+			channel:  &domain.Channel{ID: "stg", Prefix: "/", Commands: []string{"deploy"}},
+			args:     []string{"help", "st"},
+			wantKind: domaintest.ReplyBad,
+			want:     []string{"Command `/st` not found"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := domaintest.NewContext("alice", tt.args...)
+			if tt.channel != nil {
+				ctx = ctx.WithChannel(tt.channel)
+			}
+			if err := root.Execute(ctx); err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+			last, _ := ctx.Last()
+			if last.Kind != tt.wantKind {
+				t.Errorf("reply kind = %v, want %v", last.Kind, tt.wantKind)
+			}
+			msg := strings.Join(last.Message, "\n")
+			for _, want := range tt.want {
+				if !strings.Contains(msg, want) {
+					t.Errorf("reply = %q, want it to contain %q", msg, want)
+				}
+			}
+			for _, wantNot := range tt.wantNot {
+				if strings.Contains(msg, wantNot) {
+					t.Errorf("reply = %q, want it not to contain %q", msg, wantNot)
+				}
+			}
+		})
+	}
+}
+
+func TestExecute_CommandPath(t *testing.T) {
+	root := mustCompileAliases(t,
+		[]*config.CommandConfig{
+			{
+				Name: "deploy",
+				SubCommands: []*config.CommandConfig{
+					{Name: "app", TemplateRef: "echo", AllowArgs: true},
+				},
+			},
+		},
+		[]*config.AliasConfig{{Name: "dp", Command: "deploy app {name}"}},
+	)
+	config.C.PrefixMatch = true
+
+	tests := []struct {
+		name string
+		args []string
+		want []string
+	}{
+		{"command", []string{"deploy", "app", "web"}, []string{"deploy", "app"}},
+		{"alias", []string{"dp", "web"}, []string{"deploy", "app"}},
+		{"prefix match", []string{"dep", "a", "web"}, []string{"deploy", "app"}},
+		{"help", []string{"help", "deploy"}, []string{"help"}},
+		{"unknown", []string{"restart"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := domaintest.NewContext("alice", tt.args...)
+			if err := root.Execute(ctx); err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+			if got := ctx.CommandPath(); !slices.Equal(got, tt.want) {
+				t.Errorf("CommandPath() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -2,17 +2,33 @@ package bot
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"github.com/traPtitech/DevOpsBot/pkg/bot/slack"
-	"github.com/traPtitech/DevOpsBot/pkg/config"
-	"github.com/traPtitech/DevOpsBot/pkg/domain"
+	"os"
+	"os/signal"
+	"slices"
+	"syscall"
 
 	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
 
+	"github.com/traPtitech/DevOpsBot/pkg/bot/cli"
+	"github.com/traPtitech/DevOpsBot/pkg/bot/discord"
+	"github.com/traPtitech/DevOpsBot/pkg/bot/matrix"
+	"github.com/traPtitech/DevOpsBot/pkg/bot/mattermost"
+	"github.com/traPtitech/DevOpsBot/pkg/bot/slack"
 	"github.com/traPtitech/DevOpsBot/pkg/bot/traq"
+	"github.com/traPtitech/DevOpsBot/pkg/config"
+	"github.com/traPtitech/DevOpsBot/pkg/domain"
 )
 
-func Run(ctx context.Context) error {
+// errReload is returned by serve when a config reload is requested.
+var errReload = errors.New("reload requested")
+
+// Run starts the bots, and restarts them with the reloaded config on SIGHUP.
+// override optionally modifies the config loaded from the file, such as by command line flags,
+// and is applied again on each reload.
+func Run(ctx context.Context, override func(c *config.Config)) error {
 	// Initialize logger
 	logger, err := zap.NewProduction()
 	if err != nil {
@@ -20,34 +36,147 @@ func Run(ctx context.Context) error {
 	}
 	defer logger.Sync()
 
+	if override != nil {
+		override(&config.C)
+	}
+
 	// Compile commands
 	cmds, err := Compile()
 	if err != nil {
 		return fmt.Errorf("compiling commands: %w", err)
 	}
+	defer func() { cmds.Cleanup() }()
 
-	// Initialize bot
-	var bot domain.Bot
-	switch config.C.Mode {
-	case "traq":
-		bot, err = traq.NewBot(cmds, logger)
-		if err != nil {
-			return fmt.Errorf("creating traq bot: %w", err)
+	reload := make(chan os.Signal, 1)
+	signal.Notify(reload, syscall.SIGHUP)
+	defer signal.Stop(reload)
+
+	for {
+		err = serve(ctx, cmds, logger, reload)
+		if !errors.Is(err, errReload) {
+			return err
 		}
-	case "slack":
-		bot, err = slack.NewBot(cmds, logger)
+
+		logger.Info("reloading config")
+		newCmds, err := reloadConfig(override)
 		if err != nil {
-			return fmt.Errorf("creating slack bot: %w", err)
+			logger.Error("failed to reload config, keeping the current config", zap.Error(err))
+			continue
 		}
-	default:
-		return fmt.Errorf("unknown bot mode: %s", config.C.Mode)
+		cmds.Cleanup() // Bots using the previous commands are stopped
+		cmds = newCmds
 	}
+}
 
-	// Start bot
-	err = bot.Start(ctx)
+// reloadConfig re-reads the config file and secrets, and recompiles commands.
+// The current config is kept on error.
+func reloadConfig(override func(c *config.Config)) (*RootCommand, error) {
+	current := config.C
+	err := config.Load()
+	if err != nil {
+		return nil, err
+	}
+	if override != nil {
+		override(&config.C)
+	}
+	cmds, err := Compile()
 	if err != nil {
-		return fmt.Errorf("starting bot: %w", err)
+		config.C = current
+		return nil, fmt.Errorf("compiling commands: %w", err)
+	}
+	return cmds, nil
+}
+
+// serve runs the bots until ctx is done or a reload is requested.
+func serve(ctx context.Context, cmds domain.Command, logger *zap.Logger, reload <-chan os.Signal) error {
+	// Initialize bots
+	if len(config.C.Mode) == 0 {
+		return fmt.Errorf("no bot mode specified")
+	}
+	bots := make([]domain.Bot, 0, len(config.C.Mode))
+	for i, mode := range config.C.Mode {
+		if slices.Contains(config.C.Mode[:i], mode) {
+			return fmt.Errorf("duplicate bot mode: %s", mode)
+		}
+		bot, err := newBot(mode, cmds, logger.With(zap.String("platform", mode)))
+		if err != nil {
+			return err
+		}
+		bots = append(bots, bot)
 	}
 
-	return nil
+	// Start bots
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	eg, ctx := errgroup.WithContext(ctx)
+	for i, bot := range bots {
+		mode := config.C.Mode[i]
+		eg.Go(func() error {
+			err := bot.Start(ctx)
+			if err != nil {
+				return fmt.Errorf("starting %s bot: %w", mode, err)
+			}
+			return nil
+		})
+	}
+	if config.C.Health.Addr != "" {
+		eg.Go(func() error {
+			return serveHealth(ctx, config.C.Health.Addr, healthHandler(config.C.Mode, bots), logger)
+		})
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- eg.Wait() }()
+	select {
+	case err := <-done:
+		return err
+	case <-reload:
+		// Stop bots, while running commands continue to reply
+		cancel()
+		<-done
+		return errReload
+	}
+}
+
+func newBot(mode string, cmds domain.Command, logger *zap.Logger) (domain.Bot, error) {
+	switch mode {
+	case "traq":
+		bot, err := traq.NewBot(cmds, logger)
+		if err != nil {
+			return nil, fmt.Errorf("creating traq bot: %w", err)
+		}
+		return bot, nil
+	case "slack":
+		bot, err := slack.NewBot(cmds, logger)
+		if err != nil {
+			return nil, fmt.Errorf("creating slack bot: %w", err)
+		}
+		return bot, nil
+	case "mattermost":
+		bot, err := mattermost.NewBot(cmds, logger)
+		if err != nil {
+			return nil, fmt.Errorf("creating mattermost bot: %w", err)
+		}
+		return bot, nil
+	case "discord":
+		bot, err := discord.NewBot(cmds, logger)
+		if err != nil {
+			return nil, fmt.Errorf("creating discord bot: %w", err)
+		}
+		return bot, nil
+	case "matrix":
+		bot, err := matrix.NewBot(cmds, logger)
+		if err != nil {
+			return nil, fmt.Errorf("creating matrix bot: %w", err)
+		}
+		return bot, nil
+	case "cli":
+		bot, err := cli.NewBot(cmds, logger)
+		if err != nil {
+			return nil, fmt.Errorf("creating cli bot: %w", err)
+		}
+		return bot, nil
+	default:
+		return nil, fmt.Errorf("unknown bot mode: %s", mode)
+	}
 }
@@ -0,0 +1,116 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/kballard/go-shellquote"
+	"go.uber.org/zap"
+
+	"github.com/traPtitech/DevOpsBot/pkg/config"
+	"github.com/traPtitech/DevOpsBot/pkg/domain"
+)
+
+const promptText = "> "
+
+type cliBot struct {
+	in      io.Reader
+	out     io.Writer
+	rootCmd domain.Command
+	logger  *zap.Logger
+}
+
+// NewBot creates a bot which reads commands from stdin and writes replies to stdout.
+// This is useful for testing command trees locally, without connecting to any chat platform.
+func NewBot(rootCmd domain.Command, logger *zap.Logger) (domain.Bot, error) {
+	if config.C.CLI.Executor == "" {
+		return nil, fmt.Errorf("cli.executor needs to be set")
+	}
+	return &cliBot{
+		in:      os.Stdin,
+		out:     os.Stdout,
+		rootCmd: rootCmd,
+		logger:  logger,
+	}, nil
+}
+
+func (b *cliBot) Start(ctx context.Context) error {
+	lines := make(chan string)
+	scanErr := make(chan error, 1)
+	go func() {
+		defer close(lines)
+		sc := bufio.NewScanner(b.in)
+		for sc.Scan() {
+			select {
+			case lines <- sc.Text():
+			case <-ctx.Done():
+				return
+			}
+		}
+		scanErr <- sc.Err()
+	}()
+
+	_, _ = fmt.Fprintf(b.out, "Executing commands as %s. Type Ctrl+D to exit.\n", config.C.CLI.Executor)
+	for {
+		_, _ = fmt.Fprint(b.out, promptText)
+		select {
+		case <-ctx.Done():
+			return nil
+		case line, ok := <-lines:
+			if !ok {
+				_, _ = fmt.Fprintln(b.out)
+				select {
+				case err := <-scanErr:
+					return err
+				default:
+					return nil
+				}
+			}
+			b.handleLine(ctx, line)
+		}
+	}
+}
+
+func (b *cliBot) handleLine(ctx context.Context, line string) {
+	commandText := strings.TrimSpace(line)
+	// Prefix is optional in CLI, but strip it if given so that chat messages can be pasted as-is
+	commandText = strings.TrimPrefix(commandText, config.C.Prefix)
+	if commandText == "" {
+		return
+	}
+
+	// Prepare command args
+	cctx := &cliContext{
+		Context: ctx,
+
+		out:    b.out,
+		logger: b.logger,
+
+		command:    commandText,
+		executorID: config.C.CLI.Executor,
+		args:       nil,
+		replyOptions: domain.ReplyOptions{
+			Thread:    config.C.Thread.Enabled,
+			Broadcast: config.C.Thread.Broadcast,
+		},
+	}
+	args, err := shellquote.Split(commandText)
+	if err != nil {
+		_ = cctx.ReplyBad(domain.Textf("failed to parse arguments: %v", err))
+		return
+	}
+	if len(args) == 0 {
+		return
+	}
+	cctx.args = args
+
+	// Execute
+	err = b.rootCmd.Execute(cctx)
+	if err != nil {
+		cctx.L().Error("failed to execute command", zap.Error(err))
+	}
+}
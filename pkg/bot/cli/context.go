@@ -0,0 +1,126 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/samber/lo"
+	"go.uber.org/zap"
+
+	"github.com/traPtitech/DevOpsBot/pkg/config"
+	"github.com/traPtitech/DevOpsBot/pkg/domain"
+)
+
+type cliContext struct {
+	context.Context
+
+	out    io.Writer
+	logger *zap.Logger
+
+	command      string
+	executorID   string
+	args         []string
+	replyOptions domain.ReplyOptions
+}
+
+func (ctx *cliContext) Platform() string {
+	return "cli"
+}
+
+func (ctx *cliContext) Channel() *domain.Channel {
+	return &domain.Channel{
+		ID:     "cli",
+		Prefix: config.C.Prefix,
+	}
+}
+
+func (ctx *cliContext) Executor() string {
+	return ctx.executorID
+}
+
+func (ctx *cliContext) Args() []string {
+	return ctx.args
+}
+
+func (ctx *cliContext) ShiftArgs() domain.Context {
+	newCtx := *ctx
+	newCtx.args = newCtx.args[1:]
+	return &newCtx
+}
+
+func (ctx *cliContext) WithArgs(args []string) domain.Context {
+	newCtx := *ctx
+	newCtx.args = args
+	return &newCtx
+}
+
+func (ctx *cliContext) ReplyOptions() domain.ReplyOptions {
+	return ctx.replyOptions
+}
+
+func (ctx *cliContext) WithReplyOptions(opts domain.ReplyOptions) domain.Context {
+	newCtx := *ctx
+	newCtx.replyOptions = opts
+	return &newCtx
+}
+
+func (ctx *cliContext) L() *zap.Logger {
+	return ctx.logger.With(
+		zap.String("executor", ctx.Executor()),
+		zap.String("command", ctx.command),
+	)
+}
+
+func (ctx *cliContext) MessageLimit() int {
+	return config.C.CLI.MessageLimit
+}
+
+func (ctx *cliContext) StampNames() *domain.StampNames {
+	return &domain.StampNames{
+		BadCommand: config.C.Stamps.BadCommand,
+		Forbid:     config.C.Stamps.Forbid,
+		Success:    config.C.Stamps.Success,
+		Failure:    config.C.Stamps.Failure,
+		Running:    config.C.Stamps.Running,
+	}
+}
+
+// replyWithStamp prints the stamp the chat adapters would push to the command message, followed by the reply message.
+func (ctx *cliContext) replyWithStamp(kind string, stamp string, final bool, message ...domain.Block) error {
+	if ctx.replyOptions.Thread {
+		kind += lo.Ternary(final && ctx.replyOptions.Broadcast, ", in thread and channel", ", in thread")
+	}
+	if stamp != "" {
+		_, _ = fmt.Fprintf(ctx.out, "[%s] :%s:\n", kind, stamp)
+	} else {
+		_, _ = fmt.Fprintf(ctx.out, "[%s]\n", kind)
+	}
+	if len(message) > 0 {
+		_, err := fmt.Fprintln(ctx.out, domain.PlainText(message))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (ctx *cliContext) ReplyBad(message ...domain.Block) error {
+	return ctx.replyWithStamp("bad", config.C.Stamps.BadCommand, true, message...)
+}
+
+func (ctx *cliContext) ReplyForbid(message ...domain.Block) error {
+	return ctx.replyWithStamp("forbid", config.C.Stamps.Forbid, true, message...)
+}
+
+func (ctx *cliContext) ReplySuccess(message ...domain.Block) error {
+	return ctx.replyWithStamp("success", config.C.Stamps.Success, true, message...)
+}
+
+func (ctx *cliContext) ReplyFailure(message ...domain.Block) error {
+	return ctx.replyWithStamp("failure", config.C.Stamps.Failure, true, message...)
+}
+
+func (ctx *cliContext) ReplyRunning(message ...domain.Block) error {
+	return ctx.replyWithStamp("running", config.C.Stamps.Running, false, message...)
+}
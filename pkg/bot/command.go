@@ -2,10 +2,13 @@ package bot
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
+	"io/fs"
 	"log/slog"
 	"os"
 	"os/exec"
+	"regexp"
 	"slices"
 	"strings"
 
@@ -17,31 +20,45 @@ import (
 )
 
 var (
-	_ domain.Command = (*RootCommand)(nil)
-	_ domain.Command = (*CommandInstance)(nil)
+	_ domain.Command       = (*RootCommand)(nil)
+	_ domain.CommandLister = (*RootCommand)(nil)
+	_ domain.Command       = (*CommandInstance)(nil)
 )
 
 type RootCommand struct {
-	cmds map[string]domain.Command
+	cmds    map[string]domain.Command
+	aliases map[string]*alias
+	// tmpFiles are the command files created on compilation, removed by Cleanup.
+	tmpFiles []string
 }
 
 type CommandInstance struct {
-	leadingMatcher []string
-	name           string
-	description    string
-	allowArgs      bool
-	argsSyntax     string
-	argsPrefix     []string
-	operators      []string
+	leadingMatcher  []string
+	name            string
+	description     string
+	longDescription string
+	examples        []string
+	allowArgs       bool
+	argsSyntax      string
+	args            []*domain.Arg
+	argsPrefix      []string
+	operators       []string
+	allowDM         bool
+	replyOptions    domain.ReplyOptions
 
 	commandFile string
 	subCommands map[string]domain.Command
 }
 
-func Compile() (*RootCommand, error) {
+func Compile() (_ *RootCommand, err error) {
 	cmd := &RootCommand{
 		cmds: make(map[string]domain.Command),
 	}
+	defer func() {
+		if err != nil {
+			cmd.Cleanup()
+		}
+	}()
 
 	// Compile templates
 	templates := make(map[string]string, len(config.C.Templates)) // template name to filename
@@ -66,6 +83,7 @@ func Compile() (*RootCommand, error) {
 			if err != nil {
 				return nil, fmt.Errorf("creating command file: %w", err)
 			}
+			cmd.tmpFiles = append(cmd.tmpFiles, f.Name())
 			err = f.Chmod(0755)
 			if err != nil {
 				return nil, fmt.Errorf("changing file permission: %w", err)
@@ -84,8 +102,12 @@ func Compile() (*RootCommand, error) {
 		templates[tc.Name] = filename
 	}
 
-	var err error
-	cmd.cmds, err = compileCommands(templates, config.C.Commands, nil, nil)
+	cmd.cmds, err = compileCommands(templates, config.C.Commands, nil, inheritedConfig{
+		replyOptions: domain.ReplyOptions{
+			Thread:    config.C.Thread.Enabled,
+			Broadcast: config.C.Thread.Broadcast,
+		},
+	})
 	if err != nil {
 		return nil, fmt.Errorf("compiling root command: %w", err)
 	}
@@ -96,10 +118,47 @@ func Compile() (*RootCommand, error) {
 	}
 	cmd.cmds["help"] = &HelpCommand{root: cmd}
 
+	// Compile aliases
+	cmd.aliases, err = compileAliases(config.C.Aliases, cmd.cmds)
+	if err != nil {
+		return nil, fmt.Errorf("compiling aliases: %w", err)
+	}
+
+	// Validate users
+	if err := config.C.Users.Validate(); err != nil {
+		return nil, fmt.Errorf("validating users: %w", err)
+	}
+
+	// Validate per-channel command lists
+	for _, channel := range config.C.AllChannels() {
+		for _, name := range channel.Commands {
+			if _, ok := cmd.cmds[name]; !ok {
+				return nil, fmt.Errorf("channel %s: unknown command %s", channel.ID, name)
+			}
+		}
+	}
+
 	return cmd, nil
 }
 
-func compileCommands(templates map[string]string, cc []*config.CommandConfig, leadingMatcher []string, parentOperators []string) (map[string]domain.Command, error) {
+// Cleanup removes the command files created on compilation.
+func (dc *RootCommand) Cleanup() {
+	for _, file := range dc.tmpFiles {
+		if err := os.Remove(file); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			slog.Warn(fmt.Sprintf("Removing command file %s: %v", file, err))
+		}
+	}
+	dc.tmpFiles = nil
+}
+
+// inheritedConfig is the compiled config of the parent command, inherited by sub-commands.
+type inheritedConfig struct {
+	operators    []string
+	allowDM      bool
+	replyOptions domain.ReplyOptions
+}
+
+func compileCommands(templates map[string]string, cc []*config.CommandConfig, leadingMatcher []string, parent inheritedConfig) (map[string]domain.Command, error) {
 	cmds := make(map[string]domain.Command)
 
 	for _, ci := range cc {
@@ -114,12 +173,12 @@ func compileCommands(templates map[string]string, cc []*config.CommandConfig, le
 			return nil, fmt.Errorf("no self command or sub-commands defined")
 		}
 		operators := ci.Operators // If the parent allows everyone, this command's configuration is used
-		if len(parentOperators) > 0 {
+		if len(parent.operators) > 0 {
 			// Take intersection with parent operators config, if parent has set one
 			if len(operators) == 0 {
-				operators = parentOperators // This command allows everyone, just inherit the parent operators
+				operators = parent.operators // This command allows everyone, just inherit the parent operators
 			} else {
-				operators = lo.Intersect(operators, parentOperators)
+				operators = lo.Intersect(operators, parent.operators)
 				// Ensure the intersection is not empty
 				if len(operators) == 0 {
 					return nil, fmt.Errorf(
@@ -135,16 +194,38 @@ func compileCommands(templates map[string]string, cc []*config.CommandConfig, le
 			}
 		}
 
+		args, err := compileArgs(ci.Args)
+		if err != nil {
+			return nil, fmt.Errorf("compiling args of %s: %w", ci.Name, err)
+		}
+		argsSyntax := ci.ArgsSyntax
+		if argsSyntax == "" {
+			argsSyntax = domain.ArgsSyntax(args)
+		}
+
+		replyOptions := parent.replyOptions
+		if ci.Thread != nil {
+			replyOptions.Thread = *ci.Thread
+		}
+		if ci.ThreadBroadcast != nil {
+			replyOptions.Broadcast = *ci.ThreadBroadcast
+		}
+
 		// Create a command instance
 		cmd := &CommandInstance{
-			leadingMatcher: utils.Copy(leadingMatcher),
-			name:           ci.Name,
-			description:    ci.Description,
-			allowArgs:      ci.AllowArgs,
-			argsSyntax:     ci.ArgsSyntax,
-			argsPrefix:     ci.ArgsPrefix,
-			operators:      operators,
-			subCommands:    make(map[string]domain.Command),
+			leadingMatcher:  utils.Copy(leadingMatcher),
+			name:            ci.Name,
+			description:     ci.Description,
+			longDescription: ci.LongDescription,
+			examples:        ci.Examples,
+			allowArgs:       ci.AllowArgs || len(args) > 0,
+			argsSyntax:      argsSyntax,
+			args:            args,
+			argsPrefix:      ci.ArgsPrefix,
+			operators:       operators,
+			allowDM:         ci.AllowDM || parent.allowDM,
+			replyOptions:    replyOptions,
+			subCommands:     make(map[string]domain.Command),
 		}
 
 		// Command (self)
@@ -157,8 +238,11 @@ func compileCommands(templates map[string]string, cc []*config.CommandConfig, le
 		}
 
 		// Sub-commands, if any
-		var err error
-		cmd.subCommands, err = compileCommands(templates, ci.SubCommands, append(leadingMatcher, ci.Name), operators)
+		cmd.subCommands, err = compileCommands(templates, ci.SubCommands, append(leadingMatcher, ci.Name), inheritedConfig{
+			operators:    operators,
+			allowDM:      cmd.allowDM,
+			replyOptions: replyOptions,
+		})
 		if err != nil {
 			return nil, fmt.Errorf("compiling sub-commands of %s: %w", ci.Name, err)
 		}
@@ -169,19 +253,95 @@ func compileCommands(templates map[string]string, cc []*config.CommandConfig, le
 	return cmds, nil
 }
 
+func compileArgs(acs []*config.ArgConfig) ([]*domain.Arg, error) {
+	args := make([]*domain.Arg, 0, len(acs))
+	for i, ac := range acs {
+		if ac.Name == "" {
+			return nil, fmt.Errorf("argument needs a name")
+		}
+		if lo.ContainsBy(acs[:i], func(prev *config.ArgConfig) bool { return prev.Name == ac.Name }) {
+			return nil, fmt.Errorf("argument name %s conflict", ac.Name)
+		}
+		if ac.Required && i > 0 && !acs[i-1].Required {
+			return nil, fmt.Errorf("required argument %s cannot come after optional arguments", ac.Name)
+		}
+		if len(ac.Enum) > 0 && ac.Pattern != "" {
+			return nil, fmt.Errorf("argument %s cannot have both enum and pattern set", ac.Name)
+		}
+
+		arg := &domain.Arg{
+			Name:        ac.Name,
+			Description: ac.Description,
+			Required:    ac.Required,
+			Enum:        ac.Enum,
+		}
+		if ac.Pattern != "" {
+			pattern, err := regexp.Compile(`^(?:` + ac.Pattern + `)$`)
+			if err != nil {
+				return nil, fmt.Errorf("argument %s has invalid pattern: %w", ac.Name, err)
+			}
+			arg.Pattern = pattern
+		}
+		args = append(args, arg)
+	}
+	return args, nil
+}
+
 func (dc *RootCommand) Execute(ctx domain.Context) error {
 	slog.Info("Executing command", "args", ctx.Args(), "executor", ctx.Executor())
 	name := ctx.Args()[0]
 
+	// If executed in direct messages, check DM operator
+	if ctx.Channel().DM && len(config.C.DM.Operators) > 0 {
+		if !lo.Contains(config.C.DM.Operators, ctx.Executor()) {
+			return ctx.ReplyForbid(domain.Textf("You do not have permission to execute commands in direct messages."))
+		}
+	}
+
+	// Resolve unambiguous prefix of commands, if enabled
+	if _, ok := dc.cmds[name]; !ok && dc.aliases[name] == nil {
+		if resolved, ok := resolvePrefix(name, dc.candidates(ctx)); ok {
+			name = resolved
+			ctx = ctx.WithArgs(append([]string{resolved}, ctx.Args()[1:]...))
+		}
+	}
+
+	// Expand aliases
+	if a, ok := dc.aliases[name]; ok {
+		args, err := a.expand(ctx.Args()[1:])
+		if err != nil {
+			return ctx.ReplyBad(
+				domain.Paragraph{domain.Text("Invalid arguments for "), domain.Code(ctx.Channel().Prefix + name), domain.Text(": " + err.Error())},
+				domain.Paragraph{domain.Text("Usage: "), domain.Code(a.syntax(ctx.Channel().Prefix))},
+			)
+		}
+		ctx = ctx.WithArgs(args)
+		name = args[0]
+	}
+
 	c, ok := dc.cmds[name]
-	if !ok {
-		return ctx.ReplyBad(fmt.Sprintf("Unrecognized command `%s`, try /help", name))
+	if !ok || !dc.isAvailable(ctx, name) {
+		message := []domain.Block{domain.Paragraph{
+			domain.Text("Unrecognized command "), domain.Code(name),
+			domain.Text(", try "), domain.Code(ctx.Channel().Prefix + "help"),
+		}}
+		if suggestions := suggest(name, dc.candidates(ctx)); len(suggestions) > 0 {
+			prefixed := lo.Map(suggestions, func(s string, _ int) string { return ctx.Channel().Prefix + s })
+			message = append(message, suggestionMessage(prefixed))
+		}
+		return ctx.ReplyBad(message...)
 	}
 
 	ctx = ctx.ShiftArgs() // Cut matching args
 	return c.Execute(ctx)
 }
 
+// isAvailable reports whether the top-level command is available in the channel of the context.
+// The intrinsic help command is available in all channels.
+func (dc *RootCommand) isAvailable(ctx domain.Context, name string) bool {
+	return name == "help" || ctx.Channel().Allows(name)
+}
+
 func (dc *RootCommand) HasSubcommands() bool {
 	return len(dc.cmds) > 0
 }
@@ -191,7 +351,10 @@ func (dc *RootCommand) GetSubcommand(verb string) (domain.Command, bool) {
 	return c, ok
 }
 
-func (dc *RootCommand) getMatchingCommand(args []string) (domain.Command, bool) {
+func (dc *RootCommand) getMatchingCommand(ctx domain.Context, args []string) (domain.Command, bool) {
+	if !dc.isAvailable(ctx, args[0]) {
+		return nil, false
+	}
 	cur, ok := dc.GetSubcommand(args[0])
 	if !ok {
 		return nil, false
@@ -205,23 +368,47 @@ func (dc *RootCommand) getMatchingCommand(args []string) (domain.Command, bool)
 	return cur, true
 }
 
-func (dc *RootCommand) HelpMessage(_ int, _ bool) []string {
-	var lines []string
+func (dc *RootCommand) List(executor string) []*domain.CommandInfo {
+	var infos []*domain.CommandInfo
+	names := lo.Keys(dc.cmds)
+	slices.Sort(names)
+	for _, name := range names {
+		infos = append(infos, dc.cmds[name].List(executor)...)
+	}
+	return infos
+}
+
+func (dc *RootCommand) ListAll() []*domain.CommandInfo {
+	var infos []*domain.CommandInfo
 	names := lo.Keys(dc.cmds)
 	slices.Sort(names)
+	for _, name := range names {
+		infos = append(infos, listAll(dc.cmds[name])...)
+	}
+	return infos
+}
+
+func (dc *RootCommand) HelpMessage(ctx domain.Context, _ bool) []*domain.ListItem {
+	var items []*domain.ListItem
+	names := lo.Filter(lo.Keys(dc.cmds), func(name string, _ int) bool { return dc.isAvailable(ctx, name) })
+	slices.Sort(names)
 	for _, name := range names {
 		cmd := dc.cmds[name]
-		lines = append(lines, cmd.HelpMessage(0, false)...)
+		items = append(items, cmd.HelpMessage(ctx, false)...)
 	}
-	return lines
+	return items
 }
 
 func (c *CommandInstance) Execute(ctx domain.Context) error {
+	ctx = ctx.WithReplyOptions(c.replyOptions)
+
 	// If this command has permitted operators defined, check operator
 	if len(c.operators) > 0 {
 		if !lo.Contains(c.operators, ctx.Executor()) {
 			// User is not allowed to execute this command (or any subcommand)
-			return ctx.ReplyForbid(fmt.Sprintf("You do not have permission to execute this command (`%s`).", c.matcher()))
+			return ctx.ReplyForbid(domain.Paragraph{
+				domain.Text("You do not have permission to execute this command ("), domain.Code(c.matcher(ctx)), domain.Text(")."),
+			})
 		}
 	}
 
@@ -229,6 +416,13 @@ func (c *CommandInstance) Execute(ctx domain.Context) error {
 	if len(ctx.Args()) > 0 {
 		subVerb := ctx.Args()[0]
 		subCmd, ok := c.subCommands[subVerb]
+		if !ok && c.commandFile == "" {
+			// Resolve unambiguous prefix of sub-commands, if enabled
+			// (only if self-command is not defined, as the arguments would be passed to it otherwise)
+			if resolved, resolvedOK := resolvePrefix(subVerb, c.candidates(ctx.Executor())); resolvedOK {
+				subCmd, ok = c.subCommands[resolved]
+			}
+		}
 		if ok {
 			// A sub-command match
 			ctx = ctx.ShiftArgs() // Cut matching args
@@ -237,32 +431,66 @@ func (c *CommandInstance) Execute(ctx domain.Context) error {
 
 		if c.commandFile == "" {
 			// Sub-commands do not match, and self-command is not defined
-			return ctx.ReplyBad(fmt.Sprintf("Unrecognized sub-command `%s`, try `%shelp`", subVerb, config.C.Prefix))
+			message := []domain.Block{domain.Paragraph{
+				domain.Text("Unrecognized sub-command "), domain.Code(subVerb),
+				domain.Text(", try "), domain.Code(ctx.Channel().Prefix + "help"),
+			}}
+			if suggestions := suggest(subVerb, c.candidates(ctx.Executor())); len(suggestions) > 0 {
+				matched := lo.Map(suggestions, func(s string, _ int) string { return c.matcher(ctx) + " " + s })
+				message = append(message, suggestionMessage(matched))
+			}
+			return ctx.ReplyBad(message...)
 		}
 	}
 
+	// This command is resolved
+	if r, ok := ctx.(domain.CommandPathRecorder); ok {
+		r.RecordCommandPath(append(slices.Clone(c.leadingMatcher), c.name))
+	}
+
 	// Self-command is not defined - error
 	if c.commandFile == "" {
 		if len(c.subCommands) > 0 {
 			// If this command has sub-commands, display help
-			var lines []string
-			lines = append(lines, fmt.Sprintf("## `%s` Usage", c.matcher()))
-			lines = append(lines, "")
-			lines = append(lines, c.HelpMessage(0, true)...)
-			return ctx.ReplyBad(lines...)
+			return ctx.ReplyBad(
+				domain.Heading{domain.Code(c.matcher(ctx)), domain.Text(" Usage")},
+				domain.List(c.HelpMessage(ctx, true)),
+			)
 		} else {
 			// Otherwise, just error
-			return ctx.ReplyBad(fmt.Sprintf("Command `%s` has no use, maybe the bot is badly configured?", c.matcher()))
+			return ctx.ReplyBad(domain.Paragraph{
+				domain.Text("Command "), domain.Code(c.matcher(ctx)), domain.Text(" has no use, maybe the bot is badly configured?"),
+			})
 		}
 	}
 
 	// Validate run command arguments (self)
 	if !c.allowArgs && len(ctx.Args()) > 0 {
-		return ctx.ReplyBad(fmt.Sprintf(
-			"Command `%s` cannot have extra arguments (you supplied `%s`)\nTry setting allowArgs: true in config to allow extra arguments",
-			c.matcher(),
-			strings.Join(ctx.Args(), " "),
-		))
+		return ctx.ReplyBad(
+			domain.Paragraph{
+				domain.Text("Command "), domain.Code(c.matcher(ctx)),
+				domain.Text(" cannot have extra arguments (you supplied "), domain.Code(strings.Join(ctx.Args(), " ")), domain.Text(")"),
+			},
+			domain.Paragraph{domain.Text("Try setting "), domain.Code("allowArgs: true"), domain.Text(" in config to allow extra arguments")},
+		)
+	}
+
+	// Validate declared arguments (self)
+	if len(c.args) > 0 {
+		if err := domain.ValidateArgs(c.args, ctx.Args()); err != nil {
+			return ctx.ReplyBad(
+				domain.Paragraph{domain.Text("Invalid arguments for "), domain.Code(c.matcher(ctx)), domain.Text(": " + err.Error())},
+				domain.Paragraph{domain.Text("Usage: "), domain.Code(c.matcher(ctx) + " " + c.argsSyntax)},
+			)
+		}
+	}
+
+	// Validate execution channel (self)
+	if ctx.Channel().DM && !c.allowDM {
+		return ctx.ReplyForbid(
+			domain.Paragraph{domain.Text("Command "), domain.Code(c.matcher(ctx)), domain.Text(" cannot be executed in direct messages")},
+			domain.Paragraph{domain.Text("Try setting "), domain.Code("allowDM: true"), domain.Text(" in config to allow direct message execution")},
+		)
 	}
 
 	// Run command (self)
@@ -281,25 +509,23 @@ func (c *CommandInstance) Execute(ctx domain.Context) error {
 	logLimit := ctx.MessageLimit() - 100 /* margin */
 
 	err := cmd.Run()
+	if r, ok := ctx.(domain.ExitCodeRecorder); ok && cmd.ProcessState != nil {
+		r.RecordExitCode(cmd.ProcessState.ExitCode())
+	}
 	if err != nil {
 		return ctx.ReplyFailure(
-			fmt.Sprintf(":%s: exec failed: %v", ctx.StampNames().Failure, err),
-			"```",
-			utils.LimitLog(utils.SafeConvertString(buf.Bytes()), logLimit),
-			"```",
+			domain.Paragraph{domain.Status(domain.StatusFailure), domain.Text(fmt.Sprintf(" exec failed: %v", err))},
+			domain.CodeBlock(utils.LimitLog(utils.SafeConvertString(buf.Bytes()), logLimit)),
 		)
 	}
 
-	var replyMessage []string
-	if buf.Len() > 0 {
-		replyMessage = append(replyMessage, fmt.Sprintf(":%s:", ctx.StampNames().Success))
-		replyMessage = append(replyMessage, "```")
-		replyMessage = append(replyMessage, utils.LimitLog(utils.SafeConvertString(buf.Bytes()), logLimit))
-		replyMessage = append(replyMessage, "```")
-	} else {
-		replyMessage = append(replyMessage, "*No output*")
+	if buf.Len() == 0 {
+		return ctx.ReplySuccess(domain.Textf("No output"))
 	}
-	return ctx.ReplySuccess(replyMessage...)
+	return ctx.ReplySuccess(
+		domain.Paragraph{domain.Status(domain.StatusSuccess)},
+		domain.CodeBlock(utils.LimitLog(utils.SafeConvertString(buf.Bytes()), logLimit)),
+	)
 }
 
 func (c *CommandInstance) HasSubcommands() bool {
@@ -311,55 +537,105 @@ func (c *CommandInstance) GetSubcommand(verb string) (domain.Command, bool) {
 	return sub, ok
 }
 
-func (c *CommandInstance) HelpMessage(indent int, formatSub bool) []string {
-	var lines []string
+func (c *CommandInstance) List(executor string) []*domain.CommandInfo {
+	if len(c.operators) > 0 && !lo.Contains(c.operators, executor) {
+		return nil // Operators are inherited, so the executor cannot execute any sub-commands either
+	}
 
-	// Command (self) usage
-	var operators string
-	if config.C.Mode == "traq" {
-		operators = strings.Join(
-			lo.Map(c.operators, func(s string, _ int) string { return `:@` + s + `:` }),
-			"",
-		)
-	} else {
-		operators = fmt.Sprintf("%d operator%s", len(c.operators), lo.Ternary(len(c.operators) == 1, "", "s"))
+	var infos []*domain.CommandInfo
+	infos = append(infos, c.info())
+	for _, subVerb := range c.subVerbs() {
+		infos = append(infos, c.subCommands[subVerb].List(executor)...)
 	}
-	if len(c.operators) == 0 {
-		operators = "everyone"
+	return infos
+}
+
+func (c *CommandInstance) info() *domain.CommandInfo {
+	return &domain.CommandInfo{
+		Path:            append(utils.Copy(c.leadingMatcher), c.name),
+		Description:     c.description,
+		LongDescription: c.longDescription,
+		Examples:        c.examples,
+		AllowArgs:       c.allowArgs,
+		ArgsSyntax:      c.argsSyntax,
+		Args:            c.args,
+		Operators:       utils.Copy(c.operators),
+		AllowDM:         c.allowDM,
+		Runnable:        c.commandFile != "",
 	}
+}
 
-	var subCommandsNum string
-	if len(c.subCommands) > 0 {
-		subCommandsNum = fmt.Sprintf(", %d sub-command%s", len(c.subCommands), lo.Ternary(len(c.subCommands) == 1, "", "s"))
+func (c *CommandInstance) subVerbs() []string {
+	subVerbs := lo.Keys(c.subCommands)
+	slices.Sort(subVerbs)
+	return subVerbs
+}
+
+// listAll lists the command and its sub-commands, regardless of operators.
+func listAll(cmd domain.Command) []*domain.CommandInfo {
+	c, ok := cmd.(*CommandInstance)
+	if !ok {
+		return cmd.List("") // Intrinsic commands
+	}
+	infos := []*domain.CommandInfo{c.info()}
+	for _, subVerb := range c.subVerbs() {
+		infos = append(infos, listAll(c.subCommands[subVerb])...)
 	}
+	return infos
+}
 
-	syntax := c.matcher()
+func (c *CommandInstance) HelpMessage(ctx domain.Context, formatSub bool) []*domain.ListItem {
+	// Command (self) usage
+	syntax := c.matcher(ctx)
 	if c.argsSyntax != "" {
 		syntax += " " + c.argsSyntax
 	}
+	content := []domain.Inline{domain.Code(syntax)}
+	if c.description != "" {
+		content = append(content, domain.Text(" - "+c.description))
+	}
+
+	content = append(content, domain.Text(" ("))
+	content = append(content, c.operatorsMessage(ctx)...)
+	if len(c.subCommands) > 0 {
+		content = append(content, domain.Text(fmt.Sprintf(", %d sub-command%s", len(c.subCommands), lo.Ternary(len(c.subCommands) == 1, "", "s"))))
+	}
+	content = append(content, domain.Text(")"))
 
-	lines = append(lines, fmt.Sprintf(
-		"%s- `%s`%s (%s%s)",
-		strings.Repeat(" ", indent),
-		syntax,
-		lo.Ternary(c.description != "", " - "+c.description, ""),
-		operators,
-		subCommandsNum,
-	))
+	item := &domain.ListItem{Content: content}
 
 	// Sub-commands usage
 	if formatSub {
-		subVerbs := lo.Keys(c.subCommands)
-		slices.Sort(subVerbs)
-		for _, subVerb := range subVerbs {
+		for _, subVerb := range c.subVerbs() {
 			subCmd := c.subCommands[subVerb]
-			lines = append(lines, subCmd.HelpMessage(indent+2, false)...)
+			item.Children = append(item.Children, subCmd.HelpMessage(ctx, false)...)
 		}
 	}
 
-	return lines
+	return []*domain.ListItem{item}
+}
+
+// operatorsMessage displays the operators in the manner of the platform.
+func (c *CommandInstance) operatorsMessage(ctx domain.Context) []domain.Inline {
+	if len(c.operators) == 0 {
+		return []domain.Inline{domain.Text("everyone")}
+	}
+	switch ctx.Platform() {
+	case "traq":
+		// User icons next to each other
+		return lo.Map(c.operators, func(s string, _ int) domain.Inline { return domain.Mention(s) })
+	default:
+		var inlines []domain.Inline
+		for i, operator := range c.operators {
+			if i > 0 {
+				inlines = append(inlines, domain.Text(", "))
+			}
+			inlines = append(inlines, domain.Mention(operator))
+		}
+		return inlines
+	}
 }
 
-func (c *CommandInstance) matcher() string {
-	return config.C.Prefix + strings.Join(append(c.leadingMatcher, c.name), " ")
+func (c *CommandInstance) matcher(ctx domain.Context) string {
+	return ctx.Channel().Prefix + strings.Join(append(c.leadingMatcher, c.name), " ")
 }
@@ -0,0 +1,635 @@
+package bot
+
+import (
+	"os"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/samber/lo"
+
+	"github.com/traPtitech/DevOpsBot/pkg/config"
+	"github.com/traPtitech/DevOpsBot/pkg/domain"
+	"github.com/traPtitech/DevOpsBot/pkg/domain/domaintest"
+)
+
+// echoTemplate prints all given arguments.
+var echoTemplate = &config.CommandTemplateConfig{
+	Name:    "echo",
+	Command: "#!/bin/sh\necho \"$@\"\n",
+}
+
+// failTemplate prints all given arguments and exits with non-zero status.
+var failTemplate = &config.CommandTemplateConfig{
+	Name:    "fail",
+	Command: "#!/bin/sh\necho \"$@\"\nexit 1\n",
+}
+
+// setConfig replaces the global config for the duration of the test.
+func setConfig(t *testing.T, templates []*config.CommandTemplateConfig, commands []*config.CommandConfig) {
+	t.Helper()
+	prev := config.C
+	t.Cleanup(func() { config.C = prev })
+
+	config.C = config.Config{
+		Mode:      []string{"traq"},
+		Prefix:    "/",
+		TmpDir:    t.TempDir(),
+		Templates: templates,
+		Commands:  commands,
+	}
+}
+
+func mustCompile(t *testing.T, templates []*config.CommandTemplateConfig, commands []*config.CommandConfig) *RootCommand {
+	t.Helper()
+	setConfig(t, templates, commands)
+	root, err := Compile()
+	if err != nil {
+		t.Fatalf("Compile() error = %v", err)
+	}
+	return root
+}
+
+// assertReplies checks the sequence of reply kinds, and that the last reply message contains wantLast.
+func assertReplies(t *testing.T, ctx *domaintest.Context, wantKinds []domaintest.ReplyKind, wantLast string) {
+	t.Helper()
+	var kinds []domaintest.ReplyKind
+	for _, r := range ctx.Replies() {
+		kinds = append(kinds, r.Kind)
+	}
+	if !slices.Equal(kinds, wantKinds) {
+		t.Fatalf("reply kinds = %v, want %v", kinds, wantKinds)
+	}
+	last, _ := ctx.Last()
+	if msg := strings.Join(last.Message, "\n"); !strings.Contains(msg, wantLast) {
+		t.Errorf("last reply = %q, want it to contain %q", msg, wantLast)
+	}
+}
+
+func TestCompile_Errors(t *testing.T) {
+	tests := []struct {
+		name      string
+		templates []*config.CommandTemplateConfig
+		commands  []*config.CommandConfig
+		channels  config.Channels
+		wantErr   string
+	}{
+		{
+			name:      "template without name",
+			templates: []*config.CommandTemplateConfig{{Command: "#!/bin/sh\n"}},
+			wantErr:   "template needs to have a name",
+		},
+		{
+			name:      "template name conflict",
+			templates: []*config.CommandTemplateConfig{echoTemplate, echoTemplate},
+			wantErr:   "template echo conflict",
+		},
+		{
+			name:      "template with both command and execFile",
+			templates: []*config.CommandTemplateConfig{{Name: "t", Command: "#!/bin/sh\n", ExecFile: "/bin/true"}},
+			wantErr:   "cannot have both command and execFile set",
+		},
+		{
+			name:      "template with neither command nor execFile",
+			templates: []*config.CommandTemplateConfig{{Name: "t"}},
+			wantErr:   "needs to have either command or execFile",
+		},
+		{
+			name:      "command without name",
+			templates: []*config.CommandTemplateConfig{echoTemplate},
+			commands:  []*config.CommandConfig{{TemplateRef: "echo"}},
+			wantErr:   "command needs a name",
+		},
+		{
+			name:      "command name conflict",
+			templates: []*config.CommandTemplateConfig{echoTemplate},
+			commands: []*config.CommandConfig{
+				{Name: "a", TemplateRef: "echo"},
+				{Name: "a", TemplateRef: "echo"},
+			},
+			wantErr: "command name a conflict",
+		},
+		{
+			name:     "command without template nor sub-commands",
+			commands: []*config.CommandConfig{{Name: "a"}},
+			wantErr:  "no self command or sub-commands defined",
+		},
+		{
+			name:     "invalid template ref",
+			commands: []*config.CommandConfig{{Name: "a", TemplateRef: "missing"}},
+			wantErr:  "invalid template ref missing",
+		},
+		{
+			name:      "sub-command error",
+			templates: []*config.CommandTemplateConfig{echoTemplate},
+			commands: []*config.CommandConfig{
+				{Name: "a", SubCommands: []*config.CommandConfig{{Name: "b"}}},
+			},
+			wantErr: "compiling sub-commands of a",
+		},
+		{
+			name:      "empty operators intersection",
+			templates: []*config.CommandTemplateConfig{echoTemplate},
+			commands: []*config.CommandConfig{
+				{
+					Name:      "a",
+					Operators: []string{"alice"},
+					SubCommands: []*config.CommandConfig{
+						{Name: "b", TemplateRef: "echo", Operators: []string{"bob"}},
+					},
+				},
+			},
+			wantErr: "there will be no operators for command b",
+		},
+		{
+			name:      "unknown channel command",
+			templates: []*config.CommandTemplateConfig{echoTemplate},
+			commands:  []*config.CommandConfig{{Name: "a", TemplateRef: "echo"}},
+			channels:  config.Channels{{ID: "ops", Commands: []string{"a", "b"}}},
+			wantErr:   "channel ops: unknown command b",
+		},
+		{
+			name:      "required arg after optional arg",
+			templates: []*config.CommandTemplateConfig{echoTemplate},
+			commands: []*config.CommandConfig{
+				{Name: "a", TemplateRef: "echo", Args: []*config.ArgConfig{{Name: "x"}, {Name: "y", Required: true}}},
+			},
+			wantErr: "required argument y cannot come after optional arguments",
+		},
+		{
+			name:      "invalid arg pattern",
+			templates: []*config.CommandTemplateConfig{echoTemplate},
+			commands: []*config.CommandConfig{
+				{Name: "a", TemplateRef: "echo", Args: []*config.ArgConfig{{Name: "x", Pattern: "("}}},
+			},
+			wantErr: "invalid pattern",
+		},
+		{
+			name:      "help override",
+			templates: []*config.CommandTemplateConfig{echoTemplate},
+			commands:  []*config.CommandConfig{{Name: "help", TemplateRef: "echo"}},
+			wantErr:   "`help` command is an intrinsic command",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setConfig(t, tt.templates, tt.commands)
+			config.C.Slack.Channels = tt.channels
+			_, err := Compile()
+			if err == nil {
+				t.Fatalf("Compile() error = nil, want error containing %q", tt.wantErr)
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Compile() error = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCompile_Cleanup(t *testing.T) {
+	root := mustCompile(t,
+		[]*config.CommandTemplateConfig{echoTemplate},
+		[]*config.CommandConfig{{Name: "ping", TemplateRef: "echo"}},
+	)
+	countFiles := func() int {
+		entries, err := os.ReadDir(config.C.TmpDir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return len(entries)
+	}
+	if n := countFiles(); n != 1 {
+		t.Fatalf("got %d command files, want 1", n)
+	}
+	root.Cleanup()
+	if n := countFiles(); n != 0 {
+		t.Errorf("got %d command files after Cleanup(), want 0", n)
+	}
+
+	// Files are removed on compilation errors
+	config.C.Commands = []*config.CommandConfig{{Name: "help", TemplateRef: "echo"}}
+	if _, err := Compile(); err == nil {
+		t.Fatalf("Compile() error = nil, want error")
+	}
+	if n := countFiles(); n != 0 {
+		t.Errorf("got %d command files after failed Compile(), want 0", n)
+	}
+}
+
+func TestCompile_OperatorInheritance(t *testing.T) {
+	root := mustCompile(t,
+		[]*config.CommandTemplateConfig{echoTemplate},
+		[]*config.CommandConfig{
+			{
+				Name:        "open",
+				TemplateRef: "echo",
+				SubCommands: []*config.CommandConfig{
+					{Name: "restricted", TemplateRef: "echo", Operators: []string{"carol"}},
+				},
+			},
+			{
+				Name:      "parent",
+				Operators: []string{"alice", "bob"},
+				SubCommands: []*config.CommandConfig{
+					{Name: "inherit", TemplateRef: "echo"},
+					{Name: "narrow", TemplateRef: "echo", Operators: []string{"bob", "carol"}},
+				},
+			},
+		},
+	)
+
+	tests := []struct {
+		path []string
+		want []string
+	}{
+		{path: []string{"open"}, want: nil},
+		{path: []string{"open", "restricted"}, want: []string{"carol"}},
+		{path: []string{"parent"}, want: []string{"alice", "bob"}},
+		{path: []string{"parent", "inherit"}, want: []string{"alice", "bob"}},
+		{path: []string{"parent", "narrow"}, want: []string{"bob"}},
+	}
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.path, " "), func(t *testing.T) {
+			c, ok := root.getMatchingCommand(domaintest.NewContext(""), tt.path)
+			if !ok {
+				t.Fatalf("command %v not found", tt.path)
+			}
+			got := c.(*CommandInstance).operators
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("operators = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExecute(t *testing.T) {
+	root := mustCompile(t,
+		[]*config.CommandTemplateConfig{echoTemplate, failTemplate},
+		[]*config.CommandConfig{
+			{Name: "noargs", TemplateRef: "echo", ArgsPrefix: []string{"prefixed"}},
+			{Name: "args", TemplateRef: "echo", AllowArgs: true, ArgsPrefix: []string{"prefixed"}},
+			{Name: "fail", TemplateRef: "fail"},
+			{
+				Name:        "self",
+				TemplateRef: "echo",
+				AllowArgs:   true,
+				SubCommands: []*config.CommandConfig{{Name: "sub", TemplateRef: "echo", ArgsPrefix: []string{"from-sub"}}},
+			},
+			{
+				Name:        "group",
+				SubCommands: []*config.CommandConfig{{Name: "sub", TemplateRef: "echo", ArgsPrefix: []string{"from-sub"}}},
+			},
+			{
+				Name:      "admin",
+				Operators: []string{"alice"},
+				SubCommands: []*config.CommandConfig{
+					{Name: "run", TemplateRef: "echo", ArgsPrefix: []string{"admin-run"}},
+				},
+			},
+		},
+	)
+
+	tests := []struct {
+		name     string
+		executor string
+		args     []string
+		// wantKinds is the expected sequence of reply kinds.
+		wantKinds []domaintest.ReplyKind
+		// wantLast is a substring expected in the last reply message.
+		wantLast string
+	}{
+		{
+			name:      "unknown command",
+			args:      []string{"missing"},
+			wantKinds: []domaintest.ReplyKind{domaintest.ReplyBad},
+			wantLast:  "Unrecognized command `missing`",
+		},
+		{
+			name:      "run without args",
+			args:      []string{"noargs"},
+			wantKinds: []domaintest.ReplyKind{domaintest.ReplyRunning, domaintest.ReplySuccess},
+			wantLast:  "prefixed",
+		},
+		{
+			name:      "reject args",
+			args:      []string{"noargs", "extra"},
+			wantKinds: []domaintest.ReplyKind{domaintest.ReplyBad},
+			wantLast:  "cannot have extra arguments (you supplied `extra`)",
+		},
+		{
+			name:      "pass args after prefix",
+			args:      []string{"args", "a", "b"},
+			wantKinds: []domaintest.ReplyKind{domaintest.ReplyRunning, domaintest.ReplySuccess},
+			wantLast:  "prefixed a b",
+		},
+		{
+			name:      "failure",
+			args:      []string{"fail"},
+			wantKinds: []domaintest.ReplyKind{domaintest.ReplyRunning, domaintest.ReplyFailure},
+			wantLast:  "exec failed: exit status 1",
+		},
+		{
+			name:      "sub-command takes precedence over self",
+			args:      []string{"self", "sub"},
+			wantKinds: []domaintest.ReplyKind{domaintest.ReplyRunning, domaintest.ReplySuccess},
+			wantLast:  "from-sub",
+		},
+		{
+			name:      "unknown sub-command falls back to self",
+			args:      []string{"self", "other"},
+			wantKinds: []domaintest.ReplyKind{domaintest.ReplyRunning, domaintest.ReplySuccess},
+			wantLast:  "other",
+		},
+		{
+			name:      "unknown sub-command without self",
+			args:      []string{"group", "other"},
+			wantKinds: []domaintest.ReplyKind{domaintest.ReplyBad},
+			wantLast:  "Unrecognized sub-command `other`, try `/help`",
+		},
+		{
+			name:      "no sub-command without self displays usage",
+			args:      []string{"group"},
+			wantKinds: []domaintest.ReplyKind{domaintest.ReplyBad},
+			wantLast:  "- `/group sub`",
+		},
+		{
+			name:      "sub-command of group",
+			args:      []string{"group", "sub"},
+			wantKinds: []domaintest.ReplyKind{domaintest.ReplyRunning, domaintest.ReplySuccess},
+			wantLast:  "from-sub",
+		},
+		{
+			name:      "forbidden",
+			executor:  "bob",
+			args:      []string{"admin", "run"},
+			wantKinds: []domaintest.ReplyKind{domaintest.ReplyForbid},
+			wantLast:  "You do not have permission to execute this command (`/admin`)",
+		},
+		{
+			name:      "permitted",
+			executor:  "alice",
+			args:      []string{"admin", "run"},
+			wantKinds: []domaintest.ReplyKind{domaintest.ReplyRunning, domaintest.ReplySuccess},
+			wantLast:  "admin-run",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := domaintest.NewContext(tt.executor, tt.args...)
+			if err := root.Execute(ctx); err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+			assertReplies(t, ctx, tt.wantKinds, tt.wantLast)
+		})
+	}
+}
+
+func TestExecute_Channel(t *testing.T) {
+	root := mustCompile(t,
+		[]*config.CommandTemplateConfig{echoTemplate},
+		[]*config.CommandConfig{
+			{Name: "status", TemplateRef: "echo"},
+			{Name: "deploy", TemplateRef: "echo"},
+		},
+	)
+	channel := &domain.Channel{ID: "stg", Prefix: "!", Commands: []string{"status"}}
+
+	tests := []struct {
+		name      string
+		args      []string
+		wantKinds []domaintest.ReplyKind
+		wantLast  string
+	}{
+		{
+			name:      "available",
+			args:      []string{"status"},
+			wantKinds: []domaintest.ReplyKind{domaintest.ReplyRunning, domaintest.ReplySuccess},
+		},
+		{
+			name:      "not available",
+			args:      []string{"deploy"},
+			wantKinds: []domaintest.ReplyKind{domaintest.ReplyBad},
+			wantLast:  "Unrecognized command `deploy`, try `!help`",
+		},
+		{
+			name:      "help is always available",
+			args:      []string{"help"},
+			wantKinds: []domaintest.ReplyKind{domaintest.ReplySuccess},
+			wantLast:  "Type `!help command-name` for more help",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := domaintest.NewContext("alice", tt.args...).WithChannel(channel)
+			if err := root.Execute(ctx); err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+			assertReplies(t, ctx, tt.wantKinds, tt.wantLast)
+		})
+	}
+}
+
+func TestExecute_DM(t *testing.T) {
+	root := mustCompile(t,
+		[]*config.CommandTemplateConfig{echoTemplate},
+		[]*config.CommandConfig{
+			{Name: "deploy", TemplateRef: "echo"},
+			{
+				Name:        "logs",
+				TemplateRef: "echo",
+				AllowDM:     true,
+				SubCommands: []*config.CommandConfig{{Name: "web", TemplateRef: "echo"}},
+			},
+		},
+	)
+	config.C.DM.Operators = []string{"alice"}
+	dm := &domain.Channel{ID: "dm", Prefix: "/", DM: true}
+
+	tests := []struct {
+		name      string
+		executor  string
+		args      []string
+		wantKinds []domaintest.ReplyKind
+		wantLast  string
+	}{
+		{
+			name:      "allowed",
+			executor:  "alice",
+			args:      []string{"logs"},
+			wantKinds: []domaintest.ReplyKind{domaintest.ReplyRunning, domaintest.ReplySuccess},
+		},
+		{
+			name:      "inherited by sub-commands",
+			executor:  "alice",
+			args:      []string{"logs", "web"},
+			wantKinds: []domaintest.ReplyKind{domaintest.ReplyRunning, domaintest.ReplySuccess},
+		},
+		{
+			name:      "not allowed",
+			executor:  "alice",
+			args:      []string{"deploy"},
+			wantKinds: []domaintest.ReplyKind{domaintest.ReplyForbid},
+			wantLast:  "Command `/deploy` cannot be executed in direct messages",
+		},
+		{
+			name:      "not a DM operator",
+			executor:  "bob",
+			args:      []string{"logs"},
+			wantKinds: []domaintest.ReplyKind{domaintest.ReplyForbid},
+			wantLast:  "You do not have permission to execute commands in direct messages.",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := domaintest.NewContext(tt.executor, tt.args...).WithChannel(dm)
+			if err := root.Execute(ctx); err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+			assertReplies(t, ctx, tt.wantKinds, tt.wantLast)
+		})
+	}
+}
+
+func TestExecute_Args(t *testing.T) {
+	root := mustCompile(t,
+		[]*config.CommandTemplateConfig{echoTemplate},
+		[]*config.CommandConfig{
+			{
+				Name:        "deploy",
+				TemplateRef: "echo",
+				Args: []*config.ArgConfig{
+					{Name: "env", Required: true, Enum: []string{"stg", "prod"}},
+					{Name: "tag", Pattern: "v[0-9.]+"},
+				},
+			},
+		},
+	)
+
+	tests := []struct {
+		name      string
+		args      []string
+		wantKinds []domaintest.ReplyKind
+		wantLast  string
+	}{
+		{
+			name:      "required only",
+			args:      []string{"deploy", "stg"},
+			wantKinds: []domaintest.ReplyKind{domaintest.ReplyRunning, domaintest.ReplySuccess},
+			wantLast:  "stg",
+		},
+		{
+			name:      "all args",
+			args:      []string{"deploy", "prod", "v1.2"},
+			wantKinds: []domaintest.ReplyKind{domaintest.ReplyRunning, domaintest.ReplySuccess},
+			wantLast:  "prod v1.2",
+		},
+		{
+			name:      "missing required",
+			args:      []string{"deploy"},
+			wantKinds: []domaintest.ReplyKind{domaintest.ReplyBad},
+			wantLast:  "missing required argument `env`",
+		},
+		{
+			name:      "not in enum",
+			args:      []string{"deploy", "dev"},
+			wantKinds: []domaintest.ReplyKind{domaintest.ReplyBad},
+			wantLast:  "Usage: `/deploy <env:stg|prod> [tag]`",
+		},
+		{
+			name:      "pattern must fully match",
+			args:      []string{"deploy", "stg", "v1.2-rc"},
+			wantKinds: []domaintest.ReplyKind{domaintest.ReplyBad},
+			wantLast:  "argument `tag` must match",
+		},
+		{
+			name:      "too many",
+			args:      []string{"deploy", "stg", "v1", "extra"},
+			wantKinds: []domaintest.ReplyKind{domaintest.ReplyBad},
+			wantLast:  "too many arguments",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := domaintest.NewContext("alice", tt.args...)
+			if err := root.Execute(ctx); err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+			assertReplies(t, ctx, tt.wantKinds, tt.wantLast)
+		})
+	}
+}
+
+func TestExecute_ReplyOptions(t *testing.T) {
+	setConfig(t, []*config.CommandTemplateConfig{echoTemplate}, []*config.CommandConfig{
+		{Name: "default", TemplateRef: "echo"},
+		{
+			Name:            "channel",
+			TemplateRef:     "echo",
+			Thread:          lo.ToPtr(false),
+			ThreadBroadcast: lo.ToPtr(false),
+			SubCommands:     []*config.CommandConfig{{Name: "sub", TemplateRef: "echo"}},
+		},
+	})
+	config.C.Thread = config.ThreadConfig{Enabled: true, Broadcast: true}
+	root, err := Compile()
+	if err != nil {
+		t.Fatalf("Compile() error = %v", err)
+	}
+
+	tests := []struct {
+		args []string
+		want domain.ReplyOptions
+	}{
+		{args: []string{"default"}, want: domain.ReplyOptions{Thread: true, Broadcast: true}},
+		{args: []string{"channel"}, want: domain.ReplyOptions{}},
+		{args: []string{"channel", "sub"}, want: domain.ReplyOptions{}},
+	}
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.args, " "), func(t *testing.T) {
+			ctx := domaintest.NewContext("alice", tt.args...)
+			if err := root.Execute(ctx); err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+			for _, r := range ctx.Replies() {
+				if r.Options != tt.want {
+					t.Errorf("%s reply options = %+v, want %+v", r.Kind, r.Options, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestList(t *testing.T) {
+	root := mustCompile(t,
+		[]*config.CommandTemplateConfig{echoTemplate},
+		[]*config.CommandConfig{
+			{Name: "ping", TemplateRef: "echo"},
+			{
+				Name:      "admin",
+				Operators: []string{"alice"},
+				SubCommands: []*config.CommandConfig{
+					{Name: "run", TemplateRef: "echo"},
+				},
+			},
+		},
+	)
+
+	tests := []struct {
+		executor string
+		want     []string
+	}{
+		{executor: "alice", want: []string{"admin", "admin run", "help", "ping"}},
+		{executor: "bob", want: []string{"help", "ping"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.executor, func(t *testing.T) {
+			var got []string
+			for _, info := range root.List(tt.executor) {
+				got = append(got, strings.Join(info.Path, " "))
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("List() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,258 @@
+package discord
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/kballard/go-shellquote"
+	"go.uber.org/zap"
+
+	"github.com/traPtitech/DevOpsBot/pkg/config"
+	"github.com/traPtitech/DevOpsBot/pkg/domain"
+)
+
+var _ domain.HealthReporter = (*discordBot)(nil)
+
+type discordBot struct {
+	session *discordgo.Session
+	rootCmd domain.Command
+	logger  *zap.Logger
+	// argNames are the declared argument names of each slash command, converted to options
+	argNames map[string][]string
+
+	mu        sync.Mutex
+	connected bool
+}
+
+func NewBot(rootCmd domain.Command, logger *zap.Logger) (domain.Bot, error) {
+	if config.C.Discord.Token == "" {
+		return nil, fmt.Errorf("discord.token needs to be set")
+	}
+	session, err := discordgo.New("Bot " + config.C.Discord.Token)
+	if err != nil {
+		return nil, fmt.Errorf("creating session: %w", err)
+	}
+	session.Identify.Intents = discordgo.IntentsGuildMessages | discordgo.IntentsDirectMessages | discordgo.IntentsMessageContent
+
+	b := &discordBot{
+		session: session,
+		rootCmd: rootCmd,
+		logger:  logger,
+	}
+	session.AddHandler(b.messageCreated)
+	session.AddHandler(b.interactionCreated)
+	// discordgo reconnects automatically - only keep track of the connection state
+	session.AddHandler(func(_ *discordgo.Session, _ *discordgo.Connect) { b.setConnected(true) })
+	session.AddHandler(func(_ *discordgo.Session, _ *discordgo.Disconnect) { b.setConnected(false) })
+	return b, nil
+}
+
+func (b *discordBot) Start(ctx context.Context) error {
+	err := b.session.Open()
+	if err != nil {
+		return fmt.Errorf("opening gateway connection: %w", err)
+	}
+	defer b.session.Close()
+
+	if config.C.Discord.SlashCommands {
+		err = b.registerCommands()
+		if err != nil {
+			return fmt.Errorf("registering slash commands: %w", err)
+		}
+	}
+
+	<-ctx.Done()
+	return nil
+}
+
+func (b *discordBot) Healthy() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !b.connected {
+		return fmt.Errorf("not connected to Discord")
+	}
+	return nil
+}
+
+func (b *discordBot) setConnected(connected bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.connected = connected
+}
+
+// registerCommands overwrites the application commands with the ones generated from the command tree.
+func (b *discordBot) registerCommands() error {
+	lister, ok := b.rootCmd.(domain.CommandLister)
+	if !ok {
+		return fmt.Errorf("command tree cannot be listed")
+	}
+	cmds, argNames, skipped := applicationCommands(lister.ListAll())
+	if len(skipped) > 0 {
+		b.logger.Warn("skipped commands with names not allowed for slash commands", zap.Strings("commands", skipped))
+	}
+
+	app, err := b.session.Application("@me")
+	if err != nil {
+		return fmt.Errorf("getting application: %w", err)
+	}
+	_, err = b.session.ApplicationCommandBulkOverwrite(app.ID, config.C.Discord.GuildID, cmds)
+	if err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	b.argNames = argNames
+	b.mu.Unlock()
+	b.logger.Info("registered slash commands", zap.Int("count", len(cmds)))
+	return nil
+}
+
+func (b *discordBot) messageCreated(_ *discordgo.Session, m *discordgo.MessageCreate) {
+	// Validate command execution context
+	if m.Author == nil || m.Author.Bot {
+		return // Ignore bots
+	}
+
+	var channel *domain.Channel
+	var commandText string
+	if m.GuildID == "" {
+		if !config.C.DM.Enabled {
+			return // Ignore direct messages
+		}
+		// Command prefix is optional in direct messages
+		channel = &domain.Channel{
+			ID:     m.ChannelID,
+			Prefix: config.C.Prefix,
+			DM:     true,
+		}
+		commandText = strings.TrimPrefix(m.Content, config.C.Prefix)
+	} else {
+		channelConfig, ok := config.C.Discord.Channels.Find(m.ChannelID)
+		if !ok {
+			return // Ignore messages not from the specified channels
+		}
+		prefix := channelConfig.CommandPrefix()
+		if !strings.HasPrefix(m.Content, prefix) {
+			return // Command prefix does not match
+		}
+		channel = toDomainChannel(channelConfig)
+		commandText = strings.TrimPrefix(m.Content, prefix)
+	}
+
+	err := b.executeCommand(m.Message, m.Author, channel, commandText)
+	if err != nil {
+		b.logger.Error("failed to execute command", zap.Error(err))
+	}
+}
+
+func (b *discordBot) interactionCreated(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	if i.Type != discordgo.InteractionApplicationCommand {
+		return
+	}
+	err := b.handleSlashCommand(s, i.Interaction)
+	if err != nil {
+		b.logger.Error("failed to handle slash command", zap.Error(err))
+	}
+}
+
+func (b *discordBot) handleSlashCommand(s *discordgo.Session, i *discordgo.Interaction) error {
+	data := i.ApplicationCommandData()
+	user := i.User // Set in direct messages
+	if i.Member != nil {
+		user = i.Member.User
+	}
+
+	var channel *domain.Channel
+	if i.GuildID == "" {
+		if !config.C.DM.Enabled {
+			return respondEphemeral(s, i, "Commands are not available in direct messages.")
+		}
+		channel = &domain.Channel{
+			ID:     i.ChannelID,
+			Prefix: config.C.Prefix,
+			DM:     true,
+		}
+	} else {
+		channelConfig, ok := config.C.Discord.Channels.Find(i.ChannelID)
+		if !ok {
+			return respondEphemeral(s, i, "Commands are not available in this channel.")
+		}
+		channel = toDomainChannel(channelConfig)
+	}
+
+	b.mu.Lock()
+	argNames := b.argNames[data.Name]
+	b.mu.Unlock()
+	commandText := interactionCommandText(&data, argNames)
+
+	// Respond with a new message to add reactions to
+	err := s.InteractionRespond(i, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content:         fmt.Sprintf("<@%s> ran `/%s`", user.ID, commandText),
+			AllowedMentions: &discordgo.MessageAllowedMentions{},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("responding to interaction: %w", err)
+	}
+	message, err := s.InteractionResponse(i)
+	if err != nil {
+		return fmt.Errorf("getting interaction response: %w", err)
+	}
+	if message.ChannelID == "" {
+		message.ChannelID = i.ChannelID
+	}
+
+	return b.executeCommand(message, user, channel, commandText)
+}
+
+func respondEphemeral(s *discordgo.Session, i *discordgo.Interaction, content string) error {
+	return s.InteractionRespond(i, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: content,
+			Flags:   discordgo.MessageFlagsEphemeral,
+		},
+	})
+}
+
+func toDomainChannel(channel *config.ChannelConfig) *domain.Channel {
+	return &domain.Channel{
+		ID:       channel.ID,
+		Prefix:   channel.CommandPrefix(),
+		Commands: channel.Commands,
+	}
+}
+
+func (b *discordBot) executeCommand(message *discordgo.Message, user *discordgo.User, channel *domain.Channel, commandText string) error {
+	// Prepare command args
+	ctx := &discordContext{
+		Context:  context.Background(),
+		session:  b.session,
+		logger:   b.logger,
+		message:  message,
+		executor: user,
+		channel:  channel,
+		command:  commandText,
+		args:     nil,
+		replyOptions: domain.ReplyOptions{
+			Thread:    config.C.Thread.Enabled,
+			Broadcast: config.C.Thread.Broadcast,
+		},
+	}
+	args, err := shellquote.Split(commandText)
+	if err != nil {
+		return ctx.ReplyBad(domain.Textf("failed to parse arguments: %v", err))
+	}
+	if len(args) == 0 {
+		return nil
+	}
+	ctx.args = args
+
+	// Execute
+	return b.rootCmd.Execute(ctx)
+}
@@ -0,0 +1,143 @@
+package discord
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/kballard/go-shellquote"
+
+	"github.com/traPtitech/DevOpsBot/pkg/domain"
+)
+
+const (
+	// descriptionLimit is the maximum length of application command and option descriptions.
+	descriptionLimit = 100
+	// choicesLimit is the maximum number of option choices.
+	choicesLimit = 25
+	// optionArgs is the name of the free-form arguments option, for commands without declared arguments.
+	optionArgs = "args"
+)
+
+// nameRegexp matches valid application command and option names.
+var nameRegexp = regexp.MustCompile(`^[-_\p{Ll}\p{N}]{1,32}$`)
+
+// applicationCommands generates application commands (slash commands) for the top-level commands.
+// It also returns the declared argument names of each command, in order, to convert interactions back to command texts.
+//
+// Declared arguments of commands without sub-commands are converted to options,
+// and the other commands take free-form arguments in a single option.
+func applicationCommands(infos []*domain.CommandInfo) (cmds []*discordgo.ApplicationCommand, argNames map[string][]string, skipped []string) {
+	argNames = make(map[string][]string)
+	for _, info := range infos {
+		if len(info.Path) != 1 {
+			continue
+		}
+		name := info.Path[0]
+		if !nameRegexp.MatchString(name) {
+			skipped = append(skipped, name)
+			continue
+		}
+
+		var subVerbs []string
+		for _, sub := range infos {
+			if len(sub.Path) == 2 && sub.Path[0] == name {
+				subVerbs = append(subVerbs, sub.Path[1])
+			}
+		}
+
+		description, _, _ := strings.Cut(info.Description, "\n")
+		if description == "" {
+			description = "Execute " + name + " command" // Description is required by Discord
+		}
+		cmd := &discordgo.ApplicationCommand{
+			Name:        name,
+			Description: truncate(description, descriptionLimit),
+		}
+
+		if len(subVerbs) == 0 && len(info.Args) > 0 && validArgNames(info.Args) {
+			for _, arg := range info.Args {
+				cmd.Options = append(cmd.Options, argOption(arg))
+				argNames[name] = append(argNames[name], arg.Name)
+			}
+		} else if len(subVerbs) > 0 || info.AllowArgs {
+			usage := info.ArgsSyntax
+			if len(subVerbs) > 0 {
+				usage = strings.Join(subVerbs, "|") + " ..."
+			}
+			if usage == "" {
+				usage = "Arguments"
+			}
+			cmd.Options = append(cmd.Options, &discordgo.ApplicationCommandOption{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        optionArgs,
+				Description: truncate(usage, descriptionLimit),
+				Required:    !info.Runnable,
+			})
+		}
+		cmds = append(cmds, cmd)
+	}
+	return cmds, argNames, skipped
+}
+
+func validArgNames(args []*domain.Arg) bool {
+	for _, arg := range args {
+		if !nameRegexp.MatchString(arg.Name) {
+			return false
+		}
+	}
+	return true
+}
+
+func argOption(arg *domain.Arg) *discordgo.ApplicationCommandOption {
+	description := arg.Description
+	if description == "" {
+		description = arg.Syntax()
+	}
+	o := &discordgo.ApplicationCommandOption{
+		Type:        discordgo.ApplicationCommandOptionString,
+		Name:        arg.Name,
+		Description: truncate(description, descriptionLimit),
+		Required:    arg.Required,
+	}
+	if len(arg.Enum) <= choicesLimit {
+		for _, v := range arg.Enum {
+			o.Choices = append(o.Choices, &discordgo.ApplicationCommandOptionChoice{Name: v, Value: v})
+		}
+	}
+	return o
+}
+
+// interactionCommandText converts the slash command interaction to the command text, without prefix.
+// argNames are the declared argument names of the command, if converted to options.
+func interactionCommandText(data *discordgo.ApplicationCommandInteractionData, argNames []string) string {
+	values := make(map[string]string, len(data.Options))
+	for _, o := range data.Options {
+		if o.Type == discordgo.ApplicationCommandOptionString {
+			values[o.Name] = o.StringValue()
+		}
+	}
+
+	if len(argNames) == 0 {
+		return strings.TrimSpace(data.Name + " " + values[optionArgs])
+	}
+	args := []string{data.Name}
+	for _, name := range argNames {
+		v, ok := values[name]
+		if !ok {
+			break // Arguments are positional
+		}
+		args = append(args, v)
+	}
+	return shellquote.Join(args...)
+}
+
+// truncate truncates the string to at most limit runes.
+func truncate(s string, limit int) string {
+	if utf8.RuneCountInString(s) <= limit {
+		return s
+	}
+	r := []rune(s)
+	return string(r[:limit-1]) + "…"
+}
@@ -0,0 +1,128 @@
+package discord
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+
+	"github.com/traPtitech/DevOpsBot/pkg/domain"
+)
+
+func TestApplicationCommands(t *testing.T) {
+	infos := []*domain.CommandInfo{
+		{Path: []string{"deploy"}, Description: "Deploy services\nmore details", Runnable: false},
+		{Path: []string{"deploy", "api"}, Runnable: true, ArgsSyntax: "<tag>"},
+		{Path: []string{"deploy", "web"}, Runnable: true},
+		{Path: []string{"help"}, ArgsSyntax: "[command-name [sub-commands...]]", Runnable: true, AllowArgs: true},
+		{Path: []string{"ping"}, Runnable: true},
+		{Path: []string{"restart"}, Runnable: true, AllowArgs: true, Args: []*domain.Arg{
+			{Name: "env", Required: true, Enum: []string{"stg", "prod"}},
+			{Name: "service", Description: "Service name"},
+		}},
+		{Path: []string{"Upper"}, Runnable: true},
+	}
+	cmds, argNames, skipped := applicationCommands(infos)
+
+	want := []*discordgo.ApplicationCommand{
+		{
+			Name:        "deploy",
+			Description: "Deploy services",
+			Options: []*discordgo.ApplicationCommandOption{
+				{Type: discordgo.ApplicationCommandOptionString, Name: "args", Description: "api|web ...", Required: true},
+			},
+		},
+		{
+			Name:        "help",
+			Description: "Execute help command",
+			Options: []*discordgo.ApplicationCommandOption{
+				{Type: discordgo.ApplicationCommandOptionString, Name: "args", Description: "[command-name [sub-commands...]]"},
+			},
+		},
+		{Name: "ping", Description: "Execute ping command"},
+		{
+			Name:        "restart",
+			Description: "Execute restart command",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type: discordgo.ApplicationCommandOptionString, Name: "env", Description: "<env:stg|prod>", Required: true,
+					Choices: []*discordgo.ApplicationCommandOptionChoice{{Name: "stg", Value: "stg"}, {Name: "prod", Value: "prod"}},
+				},
+				{Type: discordgo.ApplicationCommandOptionString, Name: "service", Description: "Service name"},
+			},
+		},
+	}
+	if !reflect.DeepEqual(cmds, want) {
+		got, _ := json.MarshalIndent(cmds, "", "  ")
+		t.Errorf("applicationCommands() =\n%s", got)
+	}
+	if want := map[string][]string{"restart": {"env", "service"}}; !reflect.DeepEqual(argNames, want) {
+		t.Errorf("argNames = %v, want %v", argNames, want)
+	}
+	if want := []string{"Upper"}; !reflect.DeepEqual(skipped, want) {
+		t.Errorf("skipped = %v, want %v", skipped, want)
+	}
+}
+
+func TestInteractionCommandText(t *testing.T) {
+	option := func(name, value string) *discordgo.ApplicationCommandInteractionDataOption {
+		return &discordgo.ApplicationCommandInteractionDataOption{Name: name, Type: discordgo.ApplicationCommandOptionString, Value: value}
+	}
+	tests := []struct {
+		name     string
+		data     *discordgo.ApplicationCommandInteractionData
+		argNames []string
+		want     string
+	}{
+		{
+			name: "no options",
+			data: &discordgo.ApplicationCommandInteractionData{Name: "ping"},
+			want: "ping",
+		},
+		{
+			name: "free-form arguments",
+			data: &discordgo.ApplicationCommandInteractionData{Name: "deploy", Options: []*discordgo.ApplicationCommandInteractionDataOption{
+				option("args", `api "v1.0.0"`),
+			}},
+			want: `deploy api "v1.0.0"`,
+		},
+		{
+			name: "declared arguments",
+			data: &discordgo.ApplicationCommandInteractionData{Name: "restart", Options: []*discordgo.ApplicationCommandInteractionDataOption{
+				option("service", "my service"),
+				option("env", "prod"),
+			}},
+			argNames: []string{"env", "service"},
+			want:     "restart prod 'my service'",
+		},
+		{
+			name: "optional argument omitted",
+			data: &discordgo.ApplicationCommandInteractionData{Name: "restart", Options: []*discordgo.ApplicationCommandInteractionDataOption{
+				option("env", "stg"),
+			}},
+			argNames: []string{"env", "service"},
+			want:     "restart stg",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := interactionCommandText(tt.data, tt.argNames); got != tt.want {
+				t.Errorf("interactionCommandText() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseColor(t *testing.T) {
+	tests := map[string]int{
+		"#56c59c": 0x56c59c,
+		"dd0204":  0xdd0204,
+		"green":   0,
+	}
+	for color, want := range tests {
+		if got := parseColor(color); got != want {
+			t.Errorf("parseColor(%q) = %#x, want %#x", color, got, want)
+		}
+	}
+}
@@ -0,0 +1,207 @@
+package discord
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"go.uber.org/zap"
+
+	"github.com/traPtitech/DevOpsBot/pkg/config"
+	"github.com/traPtitech/DevOpsBot/pkg/domain"
+	"github.com/traPtitech/DevOpsBot/pkg/utils"
+)
+
+const (
+	// messageLimit is the maximum length of Discord messages.
+	messageLimit = 2000
+	// titleLimit is the maximum length of embed titles.
+	titleLimit = 256
+)
+
+type discordContext struct {
+	context.Context
+
+	session *discordgo.Session
+	logger  *zap.Logger
+
+	// message is the command message, or the response message of the slash command
+	message      *discordgo.Message
+	executor     *discordgo.User
+	channel      *domain.Channel
+	command      string
+	args         []string
+	replyOptions domain.ReplyOptions
+}
+
+func (ctx *discordContext) Platform() string {
+	return "discord"
+}
+
+func (ctx *discordContext) Channel() *domain.Channel {
+	return ctx.channel
+}
+
+func (ctx *discordContext) Executor() string {
+	return config.C.ResolveUser("discord", ctx.executor.ID)
+}
+
+func (ctx *discordContext) Args() []string {
+	return ctx.args
+}
+
+func (ctx *discordContext) ShiftArgs() domain.Context {
+	newCtx := *ctx
+	newCtx.args = newCtx.args[1:]
+	return &newCtx
+}
+
+func (ctx *discordContext) WithArgs(args []string) domain.Context {
+	newCtx := *ctx
+	newCtx.args = args
+	return &newCtx
+}
+
+func (ctx *discordContext) ReplyOptions() domain.ReplyOptions {
+	return ctx.replyOptions
+}
+
+func (ctx *discordContext) WithReplyOptions(opts domain.ReplyOptions) domain.Context {
+	newCtx := *ctx
+	newCtx.replyOptions = opts
+	return &newCtx
+}
+
+func (ctx *discordContext) L() *zap.Logger {
+	return ctx.logger.With(
+		zap.String("executor", ctx.Executor()),
+		zap.String("command", ctx.command),
+	)
+}
+
+func (ctx *discordContext) MessageLimit() int {
+	return messageLimit
+}
+
+// StampNames returns the reaction emojis, "discord.stamps" config taking precedence over "stamps" config.
+func (ctx *discordContext) StampNames() *domain.StampNames {
+	return &domain.StampNames{
+		BadCommand: cmp.Or(config.C.Discord.Stamps.BadCommand, config.C.Stamps.BadCommand),
+		Forbid:     cmp.Or(config.C.Discord.Stamps.Forbid, config.C.Stamps.Forbid),
+		Success:    cmp.Or(config.C.Discord.Stamps.Success, config.C.Stamps.Success),
+		Failure:    cmp.Or(config.C.Discord.Stamps.Failure, config.C.Stamps.Failure),
+		Running:    cmp.Or(config.C.Discord.Stamps.Running, config.C.Stamps.Running),
+	}
+}
+
+// formatEmoji formats the reaction emoji in message text.
+// Unicode emojis are written as is, and custom emojis "name:id" are written as "<:name:id>".
+func formatEmoji(emoji string) string {
+	if strings.Contains(emoji, ":") {
+		return "<:" + strings.Trim(emoji, ":<>") + ">"
+	}
+	return emoji
+}
+
+func (ctx *discordContext) sendDiscordMessage(channelID string, data *discordgo.MessageSend) error {
+	s := ctx.session
+	return utils.WithRetry(ctx, 10, func(ctx context.Context) error {
+		_, err := s.ChannelMessageSendComplex(channelID, data, discordgo.WithContext(ctx))
+		return err
+	})
+}
+
+func (ctx *discordContext) pushDiscordReaction(emoji string) error {
+	s := ctx.session
+	channelID, messageID := ctx.message.ChannelID, ctx.message.ID
+	return utils.WithRetry(ctx, 10, func(ctx context.Context) error {
+		return s.MessageReactionAdd(channelID, messageID, emoji, discordgo.WithContext(ctx))
+	})
+}
+
+// reply posts the message as an embed, colored by the status.
+func (ctx *discordContext) reply(status string, color string, message ...domain.Block) error {
+	data := &discordgo.MessageSend{
+		Embeds: []*discordgo.MessageEmbed{{
+			Title:       truncate(status+" "+ctx.channel.Prefix+ctx.command, titleLimit),
+			Description: domain.Markdown(message, &domain.MarkdownStyle{Stamps: ctx.StampNames(), Status: formatEmoji}),
+			Color:       parseColor(color),
+		}},
+		AllowedMentions: &discordgo.MessageAllowedMentions{}, // Do not ping anyone
+	}
+	if ctx.replyOptions.Thread {
+		// Reply to the command message, instead of creating threads for every command
+		data.Reference = ctx.message.Reference()
+	}
+	return ctx.sendDiscordMessage(ctx.message.ChannelID, data)
+}
+
+func (ctx *discordContext) replyWithStamp(status string, stamp string, color string, message ...domain.Block) error {
+	if stamp != "" {
+		err := ctx.pushDiscordReaction(stamp)
+		if err != nil {
+			// Still post the reply, which carries the result
+			ctx.L().Error("failed to add reaction", zap.String("emoji", stamp), zap.Error(err))
+		}
+	}
+	if len(message) > 0 {
+		err := ctx.reply(status, color, message...)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseColor parses the hex color code. (example: "#56c59c")
+func parseColor(color string) int {
+	c, err := strconv.ParseInt(strings.TrimPrefix(color, "#"), 16, 32)
+	if err != nil {
+		return 0
+	}
+	return int(c)
+}
+
+func (ctx *discordContext) ReplyBad(message ...domain.Block) error {
+	return ctx.replyWithStamp("Bad command", ctx.StampNames().BadCommand, config.C.Discord.Colors.BadCommand, message...)
+}
+
+func (ctx *discordContext) ReplyForbid(message ...domain.Block) error {
+	return ctx.replyWithStamp("Forbidden", ctx.StampNames().Forbid, config.C.Discord.Colors.Forbid, message...)
+}
+
+func (ctx *discordContext) ReplySuccess(message ...domain.Block) error {
+	return ctx.replyWithStamp("Success", ctx.StampNames().Success, config.C.Discord.Colors.Success, message...)
+}
+
+func (ctx *discordContext) ReplyFailure(message ...domain.Block) error {
+	return ctx.replyWithStamp("Failure", ctx.StampNames().Failure, config.C.Discord.Colors.Failure, message...)
+}
+
+// postDMNotice posts an audit notice to the main channel, if executed in direct messages.
+// It is called on ReplyRunning, after the permission checks pass.
+func (ctx *discordContext) postDMNotice() {
+	if !ctx.channel.DM || !config.C.DM.Mirror {
+		return
+	}
+	mainChannel, ok := config.C.Discord.Channels.Main()
+	if !ok {
+		return
+	}
+	notice := fmt.Sprintf("<@%s> executed `%s%s` in direct message", ctx.executor.ID, config.C.Prefix, ctx.command)
+	err := ctx.sendDiscordMessage(mainChannel.ID, &discordgo.MessageSend{
+		Content:         notice,
+		AllowedMentions: &discordgo.MessageAllowedMentions{}, // Do not ping anyone
+	})
+	if err != nil {
+		ctx.L().Error("failed to post direct message audit notice", zap.Error(err))
+	}
+}
+
+func (ctx *discordContext) ReplyRunning(message ...domain.Block) error {
+	ctx.postDMNotice()
+	return ctx.replyWithStamp("Running", ctx.StampNames().Running, config.C.Discord.Colors.Running, message...)
+}
@@ -0,0 +1,24 @@
+package discord
+
+import (
+	"testing"
+
+	"github.com/traPtitech/DevOpsBot/pkg/config"
+	"github.com/traPtitech/DevOpsBot/pkg/domain"
+)
+
+func TestStampNames(t *testing.T) {
+	config.C = config.Config{
+		Stamps:  config.Stamps{Success: "white_check_mark", Failure: "x"},
+		Discord: config.DiscordConfig{Stamps: config.Stamps{Success: "✅", Running: "devops_running:123456789012345678"}},
+	}
+	ctx := &discordContext{}
+
+	got := domain.Markdown([]domain.Block{
+		domain.Paragraph{domain.Status(domain.StatusSuccess), domain.Status(domain.StatusRunning), domain.Status(domain.StatusFailure)},
+	}, &domain.MarkdownStyle{Stamps: ctx.StampNames(), Status: formatEmoji})
+	want := "✅<:devops_running:123456789012345678>x"
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
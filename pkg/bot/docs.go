@@ -0,0 +1,235 @@
+package bot
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"slices"
+	"strings"
+
+	"github.com/samber/lo"
+
+	"github.com/traPtitech/DevOpsBot/pkg/domain"
+	"github.com/traPtitech/DevOpsBot/pkg/utils"
+)
+
+// Docs describes the compiled command tree, for generating documentation.
+type Docs struct {
+	Version  string         `json:"version"`
+	Prefix   string         `json:"prefix"`
+	Commands []*CommandDocs `json:"commands"`
+	Aliases  []*AliasDocs   `json:"aliases"`
+}
+
+// CommandDocs describes a command and its sub-commands.
+type CommandDocs struct {
+	// Path is the list of verbs to reach this command from the root. (example: ["deploy", "stg"])
+	Path            []string   `json:"path"`
+	Description     string     `json:"description,omitempty"`
+	LongDescription string     `json:"longDescription,omitempty"`
+	ArgsSyntax      string     `json:"argsSyntax,omitempty"`
+	Args            []*ArgDocs `json:"args,omitempty"`
+	Examples        []string   `json:"examples,omitempty"`
+	// Operators are the effective operators, including the inherited ones. Empty if everyone is allowed.
+	Operators   []string       `json:"operators"`
+	AllowDM     bool           `json:"allowDM"`
+	Runnable    bool           `json:"runnable"`
+	SubCommands []*CommandDocs `json:"subCommands,omitempty"`
+}
+
+// ArgDocs describes a declared argument.
+type ArgDocs struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description,omitempty"`
+	Required    bool     `json:"required"`
+	Enum        []string `json:"enum,omitempty"`
+	Pattern     string   `json:"pattern,omitempty"`
+}
+
+// AliasDocs describes an alias.
+type AliasDocs struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	// Syntax is the display syntax of the alias, including the prefix. (example: "/restart <app> [ns]")
+	Syntax string `json:"syntax"`
+	// Command is the command line which the alias expands to.
+	Command string `json:"command"`
+}
+
+// Docs returns the documentation of all commands and aliases, regardless of operators and channels.
+func (dc *RootCommand) Docs(prefix string) *Docs {
+	docs := &Docs{
+		Version:  utils.Version(),
+		Prefix:   prefix,
+		Commands: []*CommandDocs{},
+		Aliases:  []*AliasDocs{},
+	}
+
+	// ListAll lists each command before its sub-commands, in the same order as help
+	byPath := make(map[string]*CommandDocs)
+	for _, info := range dc.ListAll() {
+		cd := commandDocs(info)
+		byPath[strings.Join(info.Path, " ")] = cd
+		if len(info.Path) == 1 {
+			docs.Commands = append(docs.Commands, cd)
+			continue
+		}
+		parent := byPath[strings.Join(info.Path[:len(info.Path)-1], " ")]
+		parent.SubCommands = append(parent.SubCommands, cd)
+	}
+
+	aliasNames := lo.Keys(dc.aliases)
+	slices.Sort(aliasNames)
+	for _, name := range aliasNames {
+		a := dc.aliases[name]
+		docs.Aliases = append(docs.Aliases, &AliasDocs{
+			Name:        a.name,
+			Description: a.description,
+			Syntax:      a.syntax(prefix),
+			Command:     a.command,
+		})
+	}
+	return docs
+}
+
+func commandDocs(info *domain.CommandInfo) *CommandDocs {
+	cd := &CommandDocs{
+		Path:            info.Path,
+		Description:     info.Description,
+		LongDescription: info.LongDescription,
+		ArgsSyntax:      info.ArgsSyntax,
+		Examples:        info.Examples,
+		Operators:       lo.Ternary(info.Operators != nil, info.Operators, []string{}),
+		AllowDM:         info.AllowDM,
+		Runnable:        info.Runnable,
+	}
+	for _, arg := range info.Args {
+		ad := &ArgDocs{Name: arg.Name, Description: arg.Description, Required: arg.Required, Enum: arg.Enum}
+		if arg.Pattern != nil {
+			// Strip the anchors added on compilation
+			ad.Pattern = strings.TrimSuffix(strings.TrimPrefix(arg.Pattern.String(), "^(?:"), ")$")
+		}
+		cd.Args = append(cd.Args, ad)
+	}
+	return cd
+}
+
+// Blocks renders the documentation in the reply message model, with a section for each command.
+func (d *Docs) Blocks() []domain.Block {
+	blocks := []domain.Block{
+		domain.Heading{domain.Text(fmt.Sprintf("DevOpsBot v%s Commands", d.Version))},
+	}
+	var walk func(cmds []*CommandDocs)
+	walk = func(cmds []*CommandDocs) {
+		for _, c := range cmds {
+			blocks = append(blocks, c.blocks(d.Prefix)...)
+			walk(c.SubCommands)
+		}
+	}
+	walk(d.Commands)
+
+	if len(d.Aliases) > 0 {
+		items := make(domain.List, 0, len(d.Aliases))
+		for _, a := range d.Aliases {
+			content := []domain.Inline{domain.Code(a.Syntax), domain.Text(" → "), domain.Code(d.Prefix + a.Command)}
+			if a.Description != "" {
+				content = append(content, domain.Text(" - "+a.Description))
+			}
+			items = append(items, &domain.ListItem{Content: content})
+		}
+		blocks = append(blocks, domain.Heading{domain.Text("Aliases")}, items)
+	}
+	return blocks
+}
+
+func (c *CommandDocs) blocks(prefix string) []domain.Block {
+	matcher := prefix + strings.Join(c.Path, " ")
+	blocks := []domain.Block{domain.Heading{domain.Code(matcher)}}
+	if c.Description != "" {
+		blocks = append(blocks, domain.Paragraph{domain.Text(c.Description)})
+	}
+
+	var items domain.List
+	if c.Runnable {
+		syntax := matcher
+		if c.ArgsSyntax != "" {
+			syntax += " " + c.ArgsSyntax
+		}
+		items = append(items, &domain.ListItem{Content: []domain.Inline{domain.Text("Usage: "), domain.Code(syntax)}})
+	}
+	for _, arg := range c.Args {
+		content := []domain.Inline{domain.Code(arg.Name), domain.Text(lo.Ternary(arg.Required, " (required)", " (optional)"))}
+		if arg.Description != "" {
+			content = append(content, domain.Text(" - "+arg.Description))
+		}
+		if len(arg.Enum) > 0 {
+			content = append(content, domain.Text(", one of "), domain.Code(strings.Join(arg.Enum, "|")))
+		}
+		if arg.Pattern != "" {
+			content = append(content, domain.Text(", matching "), domain.Code(arg.Pattern))
+		}
+		items = append(items, &domain.ListItem{Content: content})
+	}
+	operators := []domain.Inline{domain.Text("Operators: ")}
+	if len(c.Operators) == 0 {
+		operators = append(operators, domain.Text("everyone"))
+	}
+	for i, operator := range c.Operators {
+		if i > 0 {
+			operators = append(operators, domain.Text(", "))
+		}
+		operators = append(operators, domain.Mention(operator))
+	}
+	items = append(items, &domain.ListItem{Content: operators})
+	if c.AllowDM {
+		items = append(items, &domain.ListItem{Content: []domain.Inline{domain.Text("Can be executed in direct messages")}})
+	}
+	if len(c.SubCommands) > 0 {
+		subs := []domain.Inline{domain.Text("Sub-commands: ")}
+		for i, sub := range c.SubCommands {
+			if i > 0 {
+				subs = append(subs, domain.Text(", "))
+			}
+			subs = append(subs, domain.Code(sub.Path[len(sub.Path)-1]))
+		}
+		items = append(items, &domain.ListItem{Content: subs})
+	}
+	blocks = append(blocks, items)
+
+	if c.LongDescription != "" {
+		blocks = append(blocks, domain.Paragraph{domain.Text(strings.TrimSpace(c.LongDescription))})
+	}
+	if len(c.Examples) > 0 {
+		examples := make(domain.List, 0, len(c.Examples))
+		for _, example := range c.Examples {
+			examples = append(examples, &domain.ListItem{Content: []domain.Inline{domain.Code(matcher + " " + example)}})
+		}
+		blocks = append(blocks, domain.Paragraph{domain.Text("Examples:")}, examples)
+	}
+	return blocks
+}
+
+// Markdown renders the documentation in Markdown.
+func (d *Docs) Markdown() []byte {
+	return []byte(domain.Markdown(d.Blocks(), &domain.MarkdownStyle{}) + "\n")
+}
+
+// HTML renders the documentation in a static HTML page.
+func (d *Docs) HTML() []byte {
+	title := html.EscapeString(fmt.Sprintf("DevOpsBot v%s Commands", d.Version))
+	var sb strings.Builder
+	sb.WriteString("<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n")
+	sb.WriteString("<title>" + title + "</title>\n</head>\n<body>\n")
+	sb.WriteString(domain.HTML(d.Blocks()))
+	sb.WriteString("\n</body>\n</html>\n")
+	return []byte(sb.String())
+}
+
+// JSON renders the documentation in indented JSON.
+func (d *Docs) JSON() ([]byte, error) {
+	b, err := json.MarshalIndent(d, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("marshalling docs: %w", err)
+	}
+	return append(b, '\n'), nil
+}
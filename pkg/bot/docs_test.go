@@ -0,0 +1,123 @@
+package bot
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/traPtitech/DevOpsBot/pkg/config"
+)
+
+func docsFixture(t *testing.T) *Docs {
+	t.Helper()
+	root := mustCompileAliases(t,
+		[]*config.CommandConfig{
+			{
+				Name:            "deploy",
+				TemplateRef:     "echo",
+				Description:     "Deploy the app",
+				LongDescription: "Deploys the app.\n",
+				Examples:        []string{"stg"},
+				Args: []*config.ArgConfig{
+					{Name: "env", Description: "Environment", Required: true, Enum: []string{"stg", "prod"}},
+					{Name: "tag", Pattern: "v[0-9.]+"},
+				},
+				Operators: []string{"alice"},
+				SubCommands: []*config.CommandConfig{
+					{Name: "status", TemplateRef: "echo", AllowDM: true},
+				},
+			},
+		},
+		[]*config.AliasConfig{
+			{Name: "dp", Command: "deploy {env}", Description: "Deploy shortcut"},
+		},
+	)
+	return root.Docs("/")
+}
+
+func TestDocs_JSON(t *testing.T) {
+	b, err := docsFixture(t).JSON()
+	if err != nil {
+		t.Fatalf("JSON() error = %v", err)
+	}
+	var got Docs
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatalf("unmarshalling: %v", err)
+	}
+
+	want := []*CommandDocs{
+		{
+			Path:            []string{"deploy"},
+			Description:     "Deploy the app",
+			LongDescription: "Deploys the app.\n",
+			ArgsSyntax:      "<env:stg|prod> [tag]",
+			Args: []*ArgDocs{
+				{Name: "env", Description: "Environment", Required: true, Enum: []string{"stg", "prod"}},
+				{Name: "tag", Pattern: "v[0-9.]+"},
+			},
+			Examples:  []string{"stg"},
+			Operators: []string{"alice"},
+			Runnable:  true,
+			SubCommands: []*CommandDocs{
+				{Path: []string{"deploy", "status"}, Operators: []string{"alice"}, AllowDM: true, Runnable: true},
+			},
+		},
+		{
+			Path:        []string{"help"},
+			Description: "Display help message.",
+			ArgsSyntax:  helpArgsSyntax,
+			Operators:   []string{},
+			AllowDM:     true,
+			Runnable:    true,
+		},
+	}
+	if !reflect.DeepEqual(got.Commands, want) {
+		gotJSON, _ := json.Marshal(got.Commands)
+		wantJSON, _ := json.Marshal(want)
+		t.Errorf("commands =\n%s\nwant\n%s", gotJSON, wantJSON)
+	}
+	wantAliases := []*AliasDocs{{Name: "dp", Description: "Deploy shortcut", Syntax: "/dp <env>", Command: "deploy {env}"}}
+	if !reflect.DeepEqual(got.Aliases, wantAliases) {
+		t.Errorf("aliases = %+v, want %+v", got.Aliases, wantAliases)
+	}
+}
+
+func TestDocs_Markdown(t *testing.T) {
+	got := string(docsFixture(t).Markdown())
+	want := "## `/deploy`\n\n" +
+		"Deploy the app\n\n" +
+		"- Usage: `/deploy <env:stg|prod> [tag]`\n" +
+		"- `env` (required) - Environment, one of `stg|prod`\n" +
+		"- `tag` (optional), matching `v[0-9.]+`\n" +
+		"- Operators: alice\n" +
+		"- Sub-commands: `status`\n\n" +
+		"Deploys the app.\n\n" +
+		"Examples:\n\n" +
+		"- `/deploy stg`\n\n" +
+		"## `/deploy status`\n\n" +
+		"- Usage: `/deploy status`\n" +
+		"- Operators: alice\n" +
+		"- Can be executed in direct messages\n\n"
+	if !strings.Contains(got, want) {
+		t.Errorf("Markdown() =\n%s\nwant it to contain\n%s", got, want)
+	}
+	wantAliases := "## Aliases\n\n- `/dp <env>` → `/deploy {env}` - Deploy shortcut\n"
+	if !strings.HasSuffix(got, wantAliases) {
+		t.Errorf("Markdown() =\n%s\nwant it to end with\n%s", got, wantAliases)
+	}
+}
+
+func TestDocs_HTML(t *testing.T) {
+	got := string(docsFixture(t).HTML())
+	for _, want := range []string{
+		"<title>DevOpsBot vUNKNOWN Commands</title>",
+		"<h2><code>/deploy</code></h2>",
+		"<li>Usage: <code>/deploy &lt;env:stg|prod&gt; [tag]</code></li>",
+		"<li><code>/dp &lt;env&gt;</code> → <code>/deploy {env}</code> - Deploy shortcut</li>",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("HTML() =\n%s\nwant it to contain %q", got, want)
+		}
+	}
+}
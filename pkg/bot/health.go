@@ -0,0 +1,64 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/traPtitech/DevOpsBot/pkg/domain"
+)
+
+// healthHandler responds with 200 if all bots are healthy, and with 503 otherwise.
+// Bots not implementing domain.HealthReporter are considered healthy.
+func healthHandler(modes []string, bots []domain.Bot) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var lines []string
+		healthy := true
+		for i, bot := range bots {
+			status := "ok"
+			if reporter, ok := bot.(domain.HealthReporter); ok {
+				if err := reporter.Healthy(); err != nil {
+					healthy = false
+					status = err.Error()
+				}
+			}
+			lines = append(lines, fmt.Sprintf("%s: %s", modes[i], status))
+		}
+
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		if !healthy {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		_, _ = w.Write([]byte(strings.Join(lines, "\n") + "\n"))
+	})
+}
+
+// serveHealth serves the health check endpoint until ctx is cancelled.
+func serveHealth(ctx context.Context, addr string, handler http.Handler, logger *zap.Logger) error {
+	mux := http.NewServeMux()
+	mux.Handle("GET /healthz", handler)
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = server.Shutdown(shutdownCtx)
+	}()
+
+	logger.Info("serving health check endpoint", zap.String("addr", addr))
+	err := server.ListenAndServe()
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("serving health check endpoint: %w", err)
+	}
+	return nil
+}
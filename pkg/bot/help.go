@@ -2,48 +2,257 @@ package bot
 
 import (
 	"fmt"
+	"slices"
+	"strconv"
 	"strings"
 
-	"github.com/traPtitech/DevOpsBot/pkg/config"
+	"github.com/kballard/go-shellquote"
+	"github.com/samber/lo"
+
 	"github.com/traPtitech/DevOpsBot/pkg/domain"
 	"github.com/traPtitech/DevOpsBot/pkg/utils"
 )
 
 var _ domain.Command = (*HelpCommand)(nil)
 
+// helpArgsSyntax is the arguments syntax of the help command.
+const helpArgsSyntax = "[--mine] [--search term] [--page n] [command-name [sub-commands...]]"
+
+// helpPageMargin is the margin of the message limit for the pagination, as replies may be decorated by the platform.
+const helpPageMargin = 200
+
 type HelpCommand struct {
 	root *RootCommand
 }
 
+// helpOptions are the parsed arguments of the help command.
+type helpOptions struct {
+	// search filters the commands by names and descriptions, case-insensitively.
+	search string
+	// mine filters the commands to those which the executor is allowed to execute.
+	mine bool
+	// page is the 1-indexed page number to display.
+	page int
+	// path is the command path to display the usage of.
+	path []string
+}
+
+func parseHelpArgs(args []string) (*helpOptions, error) {
+	opts := &helpOptions{page: 1}
+	for i := 0; i < len(args); i++ {
+		switch arg := args[i]; arg {
+		case "--mine":
+			opts.mine = true
+		case "--search", "--page":
+			if i+1 >= len(args) {
+				return nil, fmt.Errorf("option `%s` needs a value", arg)
+			}
+			i++
+			if arg == "--search" {
+				opts.search = args[i]
+				continue
+			}
+			page, err := strconv.Atoi(args[i])
+			if err != nil || page < 1 {
+				return nil, fmt.Errorf("invalid page number `%s`", args[i])
+			}
+			opts.page = page
+		default:
+			if strings.HasPrefix(arg, "--") {
+				return nil, fmt.Errorf("unknown option `%s`", arg)
+			}
+			opts.path = args[i:]
+			if opts.mine || opts.search != "" {
+				return nil, fmt.Errorf("command name cannot be combined with `--mine` or `--search`")
+			}
+			return opts, nil
+		}
+	}
+	return opts, nil
+}
+
+// flags returns the options to display the other pages of the same listing.
+func (opts *helpOptions) flags() []string {
+	var flags []string
+	if opts.mine {
+		flags = append(flags, "--mine")
+	}
+	if opts.search != "" {
+		flags = append(flags, "--search", opts.search)
+	}
+	return flags
+}
+
 func (h *HelpCommand) Execute(ctx domain.Context) error {
-	var lines []string
-	args := ctx.Args()
+	if r, ok := ctx.(domain.CommandPathRecorder); ok {
+		r.RecordCommandPath([]string{"help"})
+	}
+	prefix := ctx.Channel().Prefix
+	opts, err := parseHelpArgs(ctx.Args())
+	if err != nil {
+		return ctx.ReplyBad(
+			domain.Paragraph{domain.Text("Invalid arguments for "), domain.Code(prefix + "help"), domain.Text(": " + err.Error())},
+			domain.Paragraph{domain.Text("Usage: "), domain.Code(prefix + "help " + helpArgsSyntax)},
+		)
+	}
+
+	// Filtered usage
+	if opts.mine || opts.search != "" {
+		title := "Commands"
+		if opts.mine {
+			title = "Commands you can execute"
+		}
+		header := []domain.Inline{domain.Text(title)}
+		if opts.search != "" {
+			header = append(header, domain.Text(" matching "), domain.Code(opts.search))
+		}
+		items := h.filteredHelpMessage(ctx, opts)
+		if len(items) == 0 {
+			return ctx.ReplySuccess(domain.Heading(header), domain.Textf("No commands found."))
+		}
+		return h.replyPaged(ctx, opts, []domain.Block{domain.Heading(header)}, items, nil)
+	}
 
 	// Root usage
-	if len(args) == 0 {
-		lines = append(lines, fmt.Sprintf("## DevOpsBot v%s", utils.Version()))
-		lines = append(lines, "")
-		lines = append(lines, h.root.HelpMessage(0, true)...)
-		lines = append(lines, "")
-		lines = append(lines, fmt.Sprintf("Type `%shelp command-name` for more help", config.C.Prefix))
-		return ctx.ReplySuccess(lines...)
+	if len(opts.path) == 0 {
+		var footer []domain.Block
+		if aliases := h.root.aliasHelpMessage(ctx); len(aliases) > 0 {
+			footer = append(footer, domain.Heading{domain.Text("Aliases")}, domain.List(aliases))
+		}
+		footer = append(footer, domain.Paragraph{domain.Text("Type "), domain.Code(prefix + "help command-name"), domain.Text(" for more help")})
+		return h.replyPaged(ctx, opts,
+			[]domain.Block{domain.Heading{domain.Text(fmt.Sprintf("DevOpsBot v%s", utils.Version()))}},
+			h.root.HelpMessage(ctx, true),
+			footer,
+		)
+	}
+	args := opts.path
+
+	// Alias usage, followed by the usage of the target command
+	if a, ok := h.root.aliases[args[0]]; ok && len(args) == 1 && h.root.isAvailable(ctx, a.target()) {
+		message := []domain.Block{
+			domain.Heading{domain.Code(prefix + a.name), domain.Text(" Usage")},
+			domain.List{a.helpItem(prefix)},
+		}
+		if c, ok := h.root.GetSubcommand(a.target()); ok {
+			message = append(message, domain.List(c.HelpMessage(ctx, false)))
+		}
+		return ctx.ReplySuccess(message...)
 	}
 
 	// Specific command usage
-	c, ok := h.root.getMatchingCommand(args)
+	c, ok := h.root.getMatchingCommand(ctx, args)
 	if !ok {
-		lines = append(lines, fmt.Sprintf("Command `%s%s` not found, try `%shelp`?", config.C.Prefix, strings.Join(args, " "), config.C.Prefix))
-		return ctx.ReplyBad(lines...)
+		return ctx.ReplyBad(domain.Paragraph{
+			domain.Text("Command "), domain.Code(prefix + strings.Join(args, " ")),
+			domain.Text(" not found, try "), domain.Code(prefix + "help"), domain.Text("?"),
+		})
 	}
 
-	lines = append(lines, fmt.Sprintf("## `%s%s` Usage", config.C.Prefix, strings.Join(args, " ")))
-	lines = append(lines, "")
-	lines = append(lines, c.HelpMessage(0, true)...)
+	message := []domain.Block{
+		domain.Heading{domain.Code(prefix + strings.Join(args, " ")), domain.Text(" Usage")},
+		domain.List(c.HelpMessage(ctx, true)),
+	}
+	if ci, ok := c.(*CommandInstance); ok {
+		if ci.longDescription != "" {
+			message = append(message, domain.Paragraph{domain.Text(strings.TrimSpace(ci.longDescription))})
+		}
+		if len(ci.examples) > 0 {
+			examples := make(domain.List, 0, len(ci.examples))
+			for _, example := range ci.examples {
+				examples = append(examples, &domain.ListItem{Content: []domain.Inline{domain.Code(ci.matcher(ctx) + " " + example)}})
+			}
+			message = append(message, domain.Heading{domain.Text("Examples")}, examples)
+		}
+	}
 	if c.HasSubcommands() {
-		lines = append(lines, "")
-		lines = append(lines, fmt.Sprintf("Type `%shelp command-name [sub-commands...]` for more help", config.C.Prefix))
+		message = append(message, domain.Paragraph{
+			domain.Text("Type "), domain.Code(prefix + "help command-name [sub-commands...]"), domain.Text(" for more help"),
+		})
+	}
+	return ctx.ReplySuccess(message...)
+}
+
+// filteredHelpMessage returns the usage of the commands and aliases available in the channel, filtered by the options.
+func (h *HelpCommand) filteredHelpMessage(ctx domain.Context, opts *helpOptions) []*domain.ListItem {
+	matches := func(texts ...string) bool {
+		term := strings.ToLower(opts.search)
+		for _, text := range texts {
+			if strings.Contains(strings.ToLower(text), term) {
+				return true
+			}
+		}
+		return false
+	}
+
+	infos := h.root.ListAll()
+	if opts.mine {
+		infos = h.root.List(ctx.Executor())
+	}
+	var items []*domain.ListItem
+	for _, info := range infos {
+		if !h.root.isAvailable(ctx, info.Path[0]) || !matches(strings.Join(info.Path, " "), info.Description) {
+			continue
+		}
+		if c, ok := h.root.getMatchingCommand(ctx, info.Path); ok {
+			items = append(items, c.HelpMessage(ctx, false)...)
+		}
 	}
-	return ctx.ReplySuccess(lines...)
+
+	names := lo.Keys(h.root.aliases)
+	slices.Sort(names)
+	for _, name := range names {
+		a := h.root.aliases[name]
+		if !h.root.isAvailable(ctx, a.target()) || !matches(a.name, a.description, a.command) {
+			continue
+		}
+		if opts.mine && !isVisible(h.root.cmds[a.target()], ctx.Executor()) {
+			continue
+		}
+		items = append(items, a.helpItem(ctx.Channel().Prefix))
+	}
+	return items
+}
+
+// replyPaged replies the list of items between the header and the footer,
+// split into pages if the reply exceeds the message limit.
+func (h *HelpCommand) replyPaged(ctx domain.Context, opts *helpOptions, header []domain.Block, items []*domain.ListItem, footer []domain.Block) error {
+	prefix := ctx.Channel().Prefix
+	limit := ctx.MessageLimit() - len(domain.Markdown(slices.Concat(header, footer), &domain.MarkdownStyle{})) - helpPageMargin
+	pages := paginate(items, limit)
+	if opts.page > len(pages) {
+		return ctx.ReplyBad(domain.Textf("Page %d does not exist, there are %d pages.", opts.page, len(pages)))
+	}
+
+	message := slices.Concat(header, []domain.Block{domain.List(pages[opts.page-1])}, footer)
+	if len(pages) > 1 {
+		page := domain.Paragraph{domain.Text(fmt.Sprintf("Page %d/%d", opts.page, len(pages)))}
+		if opts.page < len(pages) {
+			next := shellquote.Join(slices.Concat([]string{"help"}, opts.flags(), []string{"--page", strconv.Itoa(opts.page + 1)})...)
+			page = append(page, domain.Text(", type "), domain.Code(prefix+next), domain.Text(" for the next page"))
+		}
+		message = append(message, page)
+	}
+	return ctx.ReplySuccess(message...)
+}
+
+// paginate splits the items into pages, so that each page renders within the limit if possible.
+// Returns at least one page.
+func paginate(items []*domain.ListItem, limit int) [][]*domain.ListItem {
+	pages := [][]*domain.ListItem{nil}
+	size := 0
+	for _, item := range items {
+		itemSize := len(domain.Markdown([]domain.Block{domain.List{item}}, &domain.MarkdownStyle{})) + 1 // line break
+		cur := len(pages) - 1
+		if size+itemSize > limit && len(pages[cur]) > 0 {
+			pages = append(pages, nil)
+			cur++
+			size = 0
+		}
+		pages[cur] = append(pages[cur], item)
+		size += itemSize
+	}
+	return pages
 }
 
 func (h *HelpCommand) HasSubcommands() bool {
@@ -54,10 +263,19 @@ func (h *HelpCommand) GetSubcommand(_ string) (domain.Command, bool) {
 	return nil, false
 }
 
-func (h *HelpCommand) HelpMessage(indent int, _ bool) []string {
-	return []string{fmt.Sprintf(
-		"%s- `%shelp` - Display help message.",
-		strings.Repeat(" ", indent),
-		config.C.Prefix,
-	)}
+func (h *HelpCommand) List(_ string) []*domain.CommandInfo {
+	return []*domain.CommandInfo{{
+		Path:        []string{"help"},
+		Description: "Display help message.",
+		AllowArgs:   true,
+		ArgsSyntax:  helpArgsSyntax,
+		AllowDM:     true,
+		Runnable:    true,
+	}}
+}
+
+func (h *HelpCommand) HelpMessage(ctx domain.Context, _ bool) []*domain.ListItem {
+	return []*domain.ListItem{{
+		Content: []domain.Inline{domain.Code(ctx.Channel().Prefix + "help"), domain.Text(" - Display help message.")},
+	}}
 }
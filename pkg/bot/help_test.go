@@ -0,0 +1,423 @@
+package bot
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/traPtitech/DevOpsBot/pkg/config"
+	"github.com/traPtitech/DevOpsBot/pkg/domain"
+	"github.com/traPtitech/DevOpsBot/pkg/domain/domaintest"
+)
+
+func TestHelpCommand(t *testing.T) {
+	root := mustCompile(t,
+		[]*config.CommandTemplateConfig{echoTemplate},
+		[]*config.CommandConfig{
+			{
+				Name:        "deploy",
+				TemplateRef: "echo",
+				Description: "Deploy the app",
+				AllowArgs:   true,
+				ArgsSyntax:  "[stg|prod]",
+				Operators:   []string{"alice", "bob"},
+				SubCommands: []*config.CommandConfig{
+					{Name: "status", TemplateRef: "echo", Description: "Show status"},
+				},
+			},
+			{Name: "ping", TemplateRef: "echo"},
+		},
+	)
+
+	tests := []struct {
+		name      string
+		args      []string
+		wantKind  domaintest.ReplyKind
+		wantLines []string
+	}{
+		{
+			name:     "root",
+			args:     []string{"help"},
+			wantKind: domaintest.ReplySuccess,
+			wantLines: []string{
+				"## DevOpsBot vUNKNOWN",
+				"",
+				"- `/deploy [stg|prod]` - Deploy the app (:@alice::@bob:, 1 sub-command)",
+				"- `/help` - Display help message.",
+				"- `/ping` (everyone)",
+				"",
+				"Type `/help command-name` for more help",
+			},
+		},
+		{
+			name:     "command with sub-commands",
+			args:     []string{"help", "deploy"},
+			wantKind: domaintest.ReplySuccess,
+			wantLines: []string{
+				"## `/deploy` Usage",
+				"",
+				"- `/deploy [stg|prod]` - Deploy the app (:@alice::@bob:, 1 sub-command)",
+				"  - `/deploy status` - Show status (:@alice::@bob:)",
+				"",
+				"Type `/help command-name [sub-commands...]` for more help",
+			},
+		},
+		{
+			name:     "sub-command",
+			args:     []string{"help", "deploy", "status"},
+			wantKind: domaintest.ReplySuccess,
+			wantLines: []string{
+				"## `/deploy status` Usage",
+				"",
+				"- `/deploy status` - Show status (:@alice::@bob:)",
+			},
+		},
+		{
+			name:     "not found",
+			args:     []string{"help", "deploy", "missing"},
+			wantKind: domaintest.ReplyBad,
+			wantLines: []string{
+				"Command `/deploy missing` not found, try `/help`?",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := domaintest.NewContext("alice", tt.args...)
+			if err := root.Execute(ctx); err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+
+			replies := ctx.Replies()
+			if len(replies) != 1 {
+				t.Fatalf("got %d replies, want 1", len(replies))
+			}
+			if replies[0].Kind != tt.wantKind {
+				t.Errorf("reply kind = %v, want %v", replies[0].Kind, tt.wantKind)
+			}
+			if !slices.Equal(replies[0].Message, tt.wantLines) {
+				t.Errorf("reply message =\n%q\nwant\n%q", replies[0].Message, tt.wantLines)
+			}
+		})
+	}
+}
+
+func TestHelpMessage_Slack(t *testing.T) {
+	root := mustCompile(t,
+		[]*config.CommandTemplateConfig{echoTemplate},
+		[]*config.CommandConfig{
+			{Name: "one", TemplateRef: "echo", Operators: []string{"U01"}},
+			{Name: "two", TemplateRef: "echo", Operators: []string{"U01", "U02"}},
+		},
+	)
+	ctx := domaintest.NewContext("U01", "help").WithPlatform("slack")
+	if err := root.Execute(ctx); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	want := []string{
+		"## DevOpsBot vUNKNOWN",
+		"",
+		"- `/help` - Display help message.",
+		"- `/one` (:@U01:)",
+		"- `/two` (:@U01:, :@U02:)",
+		"",
+		"Type `/help command-name` for more help",
+	}
+	if got, _ := ctx.Last(); !slices.Equal(got.Message, want) {
+		t.Errorf("reply message =\n%q\nwant\n%q", got.Message, want)
+	}
+}
+
+func TestHelpCommand_Channel(t *testing.T) {
+	root := mustCompile(t,
+		[]*config.CommandTemplateConfig{echoTemplate},
+		[]*config.CommandConfig{
+			{Name: "status", TemplateRef: "echo"},
+			{Name: "deploy", TemplateRef: "echo"},
+		},
+	)
+	channel := &domain.Channel{ID: "stg", Prefix: "!", Commands: []string{"status"}}
+
+	ctx := domaintest.NewContext("alice", "help").WithChannel(channel)
+	if err := root.Execute(ctx); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	want := []string{
+		"## DevOpsBot vUNKNOWN",
+		"",
+		"- `!help` - Display help message.",
+		"- `!status` (everyone)",
+		"",
+		"Type `!help command-name` for more help",
+	}
+	if got, _ := ctx.Last(); !slices.Equal(got.Message, want) {
+		t.Errorf("reply message =\n%q\nwant\n%q", got.Message, want)
+	}
+
+	ctx = domaintest.NewContext("alice", "help", "deploy").WithChannel(channel)
+	if err := root.Execute(ctx); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if got, _ := ctx.Last(); got.Kind != domaintest.ReplyBad {
+		t.Errorf("reply kind = %v, want %v", got.Kind, domaintest.ReplyBad)
+	}
+}
+
+func TestHelpCommand_Filter(t *testing.T) {
+	root := mustCompileAliases(t,
+		[]*config.CommandConfig{
+			{
+				Name:        "deploy",
+				TemplateRef: "echo",
+				Description: "Deploy the app",
+				Operators:   []string{"alice"},
+				SubCommands: []*config.CommandConfig{
+					{Name: "status", TemplateRef: "echo", Description: "Show deployment status"},
+				},
+			},
+			{Name: "logs", TemplateRef: "echo", Description: "Show app logs"},
+			{Name: "ping", TemplateRef: "echo"},
+		},
+		[]*config.AliasConfig{
+			{Name: "dp", Command: "deploy", Description: "Deploy shortcut"},
+		},
+	)
+
+	tests := []struct {
+		name      string
+		executor  string
+		args      []string
+		wantKind  domaintest.ReplyKind
+		wantLines []string
+	}{
+		{
+			name:     "search names and descriptions",
+			executor: "bob",
+			args:     []string{"help", "--search", "STATUS"},
+			wantKind: domaintest.ReplySuccess,
+			wantLines: []string{
+				"## Commands matching `STATUS`",
+				"",
+				"- `/deploy status` - Show deployment status (:@alice:)",
+			},
+		},
+		{
+			name:     "search includes aliases",
+			executor: "alice",
+			args:     []string{"help", "--search", "deploy"},
+			wantKind: domaintest.ReplySuccess,
+			wantLines: []string{
+				"## Commands matching `deploy`",
+				"",
+				"- `/deploy` - Deploy the app (:@alice:, 1 sub-command)",
+				"- `/deploy status` - Show deployment status (:@alice:)",
+				"- `/dp` → `/deploy` - Deploy shortcut",
+			},
+		},
+		{
+			name:     "mine",
+			executor: "bob",
+			args:     []string{"help", "--mine"},
+			wantKind: domaintest.ReplySuccess,
+			wantLines: []string{
+				"## Commands you can execute",
+				"",
+				"- `/help` - Display help message.",
+				"- `/logs` - Show app logs (everyone)",
+				"- `/ping` (everyone)",
+			},
+		},
+		{
+			name:     "mine and search",
+			executor: "bob",
+			args:     []string{"help", "--mine", "--search", "app"},
+			wantKind: domaintest.ReplySuccess,
+			wantLines: []string{
+				"## Commands you can execute matching `app`",
+				"",
+				"- `/logs` - Show app logs (everyone)",
+			},
+		},
+		{
+			name:     "no match",
+			executor: "bob",
+			args:     []string{"help", "--search", "missing"},
+			wantKind: domaintest.ReplySuccess,
+			wantLines: []string{
+				"## Commands matching `missing`",
+				"",
+				"No commands found.",
+			},
+		},
+		{
+			name:     "unknown option",
+			executor: "bob",
+			args:     []string{"help", "--all"},
+			wantKind: domaintest.ReplyBad,
+			wantLines: []string{
+				"Invalid arguments for `/help`: unknown option `--all`",
+				"",
+				"Usage: `/help [--mine] [--search term] [--page n] [command-name [sub-commands...]]`",
+			},
+		},
+		{
+			name:     "missing search term",
+			executor: "bob",
+			args:     []string{"help", "--search"},
+			wantKind: domaintest.ReplyBad,
+			wantLines: []string{
+				"Invalid arguments for `/help`: option `--search` needs a value",
+				"",
+				"Usage: `/help [--mine] [--search term] [--page n] [command-name [sub-commands...]]`",
+			},
+		},
+		{
+			name:     "combined with command name",
+			executor: "bob",
+			args:     []string{"help", "--mine", "deploy"},
+			wantKind: domaintest.ReplyBad,
+			wantLines: []string{
+				"Invalid arguments for `/help`: command name cannot be combined with `--mine` or `--search`",
+				"",
+				"Usage: `/help [--mine] [--search term] [--page n] [command-name [sub-commands...]]`",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := domaintest.NewContext(tt.executor, tt.args...)
+			if err := root.Execute(ctx); err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+			got, _ := ctx.Last()
+			if got.Kind != tt.wantKind {
+				t.Errorf("reply kind = %v, want %v", got.Kind, tt.wantKind)
+			}
+			if !slices.Equal(got.Message, tt.wantLines) {
+				t.Errorf("reply message =\n%q\nwant\n%q", got.Message, tt.wantLines)
+			}
+		})
+	}
+}
+
+func TestHelpCommand_Pagination(t *testing.T) {
+	root := mustCompile(t,
+		[]*config.CommandTemplateConfig{echoTemplate},
+		[]*config.CommandConfig{
+			{Name: "a", TemplateRef: "echo", Description: "rollout restart " + strings.Repeat("a", 84)},
+			{Name: "b", TemplateRef: "echo", Description: "rollout restart " + strings.Repeat("b", 84)},
+			{Name: "c", TemplateRef: "echo", Description: "rollout restart " + strings.Repeat("c", 84)},
+		},
+	)
+	// Header, footer and margin take about 260 characters, and each item about 125 characters
+	const limit = 600
+
+	tests := []struct {
+		name      string
+		args      []string
+		wantKind  domaintest.ReplyKind
+		want      []string
+		wantNotIn []string
+	}{
+		{
+			name:      "first page",
+			args:      []string{"help"},
+			wantKind:  domaintest.ReplySuccess,
+			want:      []string{"`/a`", "`/b`", "Page 1/2, type `/help --page 2` for the next page"},
+			wantNotIn: []string{"`/c`"},
+		},
+		{
+			name:      "last page",
+			args:      []string{"help", "--page", "2"},
+			wantKind:  domaintest.ReplySuccess,
+			want:      []string{"`/c`", "`/help`", "Page 2/2"},
+			wantNotIn: []string{"`/a`", "next page"},
+		},
+		{
+			name:      "filtered",
+			args:      []string{"help", "--mine", "--search", "c", "--page", "1"},
+			wantKind:  domaintest.ReplySuccess,
+			want:      []string{"`/c`"},
+			wantNotIn: []string{"`/a`", "Page"},
+		},
+		{
+			name:     "quoted search term",
+			args:     []string{"help", "--search", "rollout restart"},
+			wantKind: domaintest.ReplySuccess,
+			want:     []string{"`/a`", "Page 1/2, type `/help --search 'rollout restart' --page 2` for the next page"},
+		},
+		{
+			name:     "out of range",
+			args:     []string{"help", "--page", "3"},
+			wantKind: domaintest.ReplyBad,
+			want:     []string{"Page 3 does not exist, there are 2 pages."},
+		},
+		{
+			name:     "invalid page",
+			args:     []string{"help", "--page", "0"},
+			wantKind: domaintest.ReplyBad,
+			want:     []string{"invalid page number `0`"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := domaintest.NewContext("alice", tt.args...).WithMessageLimit(limit)
+			if err := root.Execute(ctx); err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+			got, _ := ctx.Last()
+			if got.Kind != tt.wantKind {
+				t.Errorf("reply kind = %v, want %v", got.Kind, tt.wantKind)
+			}
+			msg := strings.Join(got.Message, "\n")
+			for _, want := range tt.want {
+				if !strings.Contains(msg, want) {
+					t.Errorf("reply = %q, want it to contain %q", msg, want)
+				}
+			}
+			for _, wantNot := range tt.wantNotIn {
+				if strings.Contains(msg, wantNot) {
+					t.Errorf("reply = %q, want it not to contain %q", msg, wantNot)
+				}
+			}
+		})
+	}
+}
+
+func TestHelpCommand_LongDescription(t *testing.T) {
+	root := mustCompile(t,
+		[]*config.CommandTemplateConfig{echoTemplate},
+		[]*config.CommandConfig{
+			{
+				Name:            "deploy",
+				TemplateRef:     "echo",
+				Description:     "Deploy the app",
+				LongDescription: "Deploys the app to the environment.\nRolls back automatically on failure.\n",
+				Examples:        []string{"stg", "prod v1.2.0"},
+				AllowArgs:       true,
+			},
+		},
+	)
+
+	ctx := domaintest.NewContext("alice", "help", "deploy")
+	if err := root.Execute(ctx); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	want := []string{
+		"## `/deploy` Usage",
+		"",
+		"- `/deploy` - Deploy the app (everyone)",
+		"",
+		"Deploys the app to the environment.",
+		"Rolls back automatically on failure.",
+		"",
+		"## Examples",
+		"",
+		"- `/deploy stg`",
+		"- `/deploy prod v1.2.0`",
+	}
+	if got, _ := ctx.Last(); !slices.Equal(got.Message, want) {
+		t.Errorf("reply message =\n%q\nwant\n%q", got.Message, want)
+	}
+}
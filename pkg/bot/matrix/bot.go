@@ -0,0 +1,206 @@
+package matrix
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kballard/go-shellquote"
+	"go.uber.org/zap"
+
+	"github.com/traPtitech/DevOpsBot/pkg/config"
+	"github.com/traPtitech/DevOpsBot/pkg/domain"
+)
+
+const (
+	// syncTimeout is the duration for the homeserver to wait for new events in a sync request.
+	syncTimeout = 30 * time.Second
+	// requestTimeout is the timeout of sync requests, including syncTimeout.
+	requestTimeout = syncTimeout + 30*time.Second
+
+	initialBackoff = 1 * time.Second
+	maxBackoff     = 60 * time.Second
+)
+
+var _ domain.HealthReporter = (*matrixBot)(nil)
+
+type matrixBot struct {
+	client  *client
+	rootCmd domain.Command
+	logger  *zap.Logger
+	// userID is the user ID of this bot, to ignore its own messages
+	userID string
+
+	mu      sync.Mutex
+	synced  bool
+	lastErr error
+}
+
+func NewBot(rootCmd domain.Command, logger *zap.Logger) (domain.Bot, error) {
+	if config.C.Matrix.Homeserver == "" || config.C.Matrix.Token == "" {
+		return nil, fmt.Errorf("matrix.homeserver and matrix.token need to be set")
+	}
+	c := newClient(config.C.Matrix.Homeserver, config.C.Matrix.Token)
+
+	userID, err := c.whoami(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("getting bot user: %w", err)
+	}
+
+	return &matrixBot{
+		client:  c,
+		rootCmd: rootCmd,
+		logger:  logger,
+		userID:  userID,
+	}, nil
+}
+
+// Start receives events by long-polling sync API, and retries with backoff until ctx is cancelled.
+func (b *matrixBot) Start(ctx context.Context) error {
+	var since string
+	backoff := initialBackoff
+	for {
+		res, err := b.sync(ctx, since)
+		if ctx.Err() != nil {
+			return nil // Shutting down
+		}
+		if err != nil {
+			b.setSynced(false, err)
+			b.logger.Warn("failed to sync with Matrix homeserver, retrying", zap.Error(err), zap.Duration("backoff", backoff))
+			select {
+			case <-time.After(backoff):
+			case <-ctx.Done():
+				return nil
+			}
+			backoff = min(backoff*2, maxBackoff)
+			continue
+		}
+		backoff = initialBackoff
+		b.setSynced(true, nil)
+
+		b.handleInvites(ctx, res)
+		if since != "" {
+			// Events in the initial sync were sent before the bot started
+			b.handleMessages(res)
+		}
+		since = res.NextBatch
+	}
+}
+
+func (b *matrixBot) sync(ctx context.Context, since string) (*syncResponse, error) {
+	timeout := syncTimeout
+	if since == "" {
+		timeout = 0 // Return immediately on the initial sync
+	}
+	ctx, cancel := context.WithTimeout(ctx, requestTimeout)
+	defer cancel()
+	return b.client.sync(ctx, since, timeout)
+}
+
+func (b *matrixBot) Healthy() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.synced {
+		return nil
+	}
+	if b.lastErr != nil {
+		return fmt.Errorf("not synced with Matrix homeserver: %w", b.lastErr)
+	}
+	return fmt.Errorf("not synced with Matrix homeserver")
+}
+
+func (b *matrixBot) setSynced(synced bool, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.synced = synced
+	b.lastErr = err
+}
+
+// handleInvites joins the rooms in the allowlist when invited.
+func (b *matrixBot) handleInvites(ctx context.Context, res *syncResponse) {
+	for roomID := range res.Rooms.Invite {
+		if _, ok := config.C.Matrix.Rooms.Find(roomID); !ok {
+			continue // Ignore invites to rooms not in the allowlist
+		}
+		if err := b.client.joinRoom(ctx, roomID); err != nil {
+			b.logger.Error("failed to join room", zap.String("room", roomID), zap.Error(err))
+			continue
+		}
+		b.logger.Info("joined room", zap.String("room", roomID))
+	}
+}
+
+func (b *matrixBot) handleMessages(res *syncResponse) {
+	for roomID, room := range res.Rooms.Join {
+		for _, ev := range room.Timeline.Events {
+			b.messageReceived(roomID, ev)
+		}
+	}
+}
+
+func (b *matrixBot) messageReceived(roomID string, ev *event) {
+	// Validate command execution context
+	if ev.Type != eventTypeMessage || ev.Sender == b.userID {
+		return // Ignore own messages
+	}
+	if ev.Content.MsgType != msgTypeText {
+		return // Ignore notices (sent by bots by convention), and other message types
+	}
+	if r := ev.Content.RelatesTo; r != nil && r.RelType == relTypeReplace {
+		return // Ignore edits
+	}
+
+	channelConfig, ok := config.C.Matrix.Rooms.Find(roomID)
+	if !ok {
+		return // Ignore messages not from the specified rooms
+	}
+	prefix := channelConfig.CommandPrefix()
+	if !strings.HasPrefix(ev.Content.Body, prefix) {
+		return // Command prefix does not match
+	}
+	channel := &domain.Channel{
+		ID:       channelConfig.ID,
+		Prefix:   prefix,
+		Commands: channelConfig.Commands,
+	}
+	commandText := strings.TrimPrefix(ev.Content.Body, prefix)
+
+	go func() {
+		err := b.executeCommand(roomID, ev, channel, commandText)
+		if err != nil {
+			b.logger.Error("failed to execute command", zap.Error(err))
+		}
+	}()
+}
+
+func (b *matrixBot) executeCommand(roomID string, ev *event, channel *domain.Channel, commandText string) error {
+	// Prepare command args
+	ctx := &matrixContext{
+		Context: context.Background(),
+		client:  b.client,
+		logger:  b.logger,
+		roomID:  roomID,
+		event:   ev,
+		channel: channel,
+		command: commandText,
+		args:    nil,
+		replyOptions: domain.ReplyOptions{
+			Thread:    config.C.Thread.Enabled,
+			Broadcast: config.C.Thread.Broadcast,
+		},
+		status: &statusMessage{},
+	}
+	args, err := shellquote.Split(commandText)
+	if err != nil {
+		return ctx.ReplyBad(domain.Textf("failed to parse arguments: %v", err))
+	}
+	if len(args) == 0 {
+		return nil
+	}
+	ctx.args = args
+
+	// Execute
+	return b.rootCmd.Execute(ctx)
+}
@@ -0,0 +1,249 @@
+package matrix
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/traPtitech/DevOpsBot/pkg/config"
+	"github.com/traPtitech/DevOpsBot/pkg/domain"
+)
+
+// runCommand reports running, and replies with the executor and the arguments.
+type runCommand struct{}
+
+func (runCommand) Execute(ctx domain.Context) error {
+	_ = ctx.ReplyRunning()
+	return ctx.ReplySuccess(domain.Textf("%s: %s", ctx.Executor(), strings.Join(ctx.Args(), " ")))
+}
+func (runCommand) HasSubcommands() bool                                { return false }
+func (runCommand) GetSubcommand(string) (domain.Command, bool)         { return nil, false }
+func (runCommand) HelpMessage(domain.Context, bool) []*domain.ListItem { return nil }
+func (runCommand) List(string) []*domain.CommandInfo                   { return nil }
+
+// sentEvent is an event sent by the bot.
+type sentEvent struct {
+	roomID    string
+	eventType string
+	content   map[string]any
+	// eventID is the event ID returned to the bot
+	eventID string
+}
+
+// fakeHomeserver emulates the parts of Matrix client-server API used by the bot.
+type fakeHomeserver struct {
+	*httptest.Server
+	// batches are returned by sync requests in order, after the initial sync
+	batches chan *syncResponse
+	sent    chan sentEvent
+	joined  chan string
+}
+
+func newFakeHomeserver(t *testing.T, initial *syncResponse) *fakeHomeserver {
+	t.Helper()
+	s := &fakeHomeserver{
+		batches: make(chan *syncResponse, 10),
+		sent:    make(chan sentEvent, 10),
+		joined:  make(chan string, 10),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /_matrix/client/v3/account/whoami", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"user_id":"@devops:example.org"}`))
+	})
+	mux.HandleFunc("GET /_matrix/client/v3/sync", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("since") == "" {
+			_ = json.NewEncoder(w).Encode(initial)
+			return
+		}
+		select {
+		case res := <-s.batches:
+			_ = json.NewEncoder(w).Encode(res)
+		case <-time.After(100 * time.Millisecond):
+			_, _ = w.Write([]byte(`{"next_batch":"` + r.URL.Query().Get("since") + `"}`))
+		case <-r.Context().Done():
+		}
+	})
+	mux.HandleFunc("POST /_matrix/client/v3/rooms/{room}/join", func(w http.ResponseWriter, r *http.Request) {
+		s.joined <- r.PathValue("room")
+		_, _ = w.Write([]byte(`{"room_id":"` + r.PathValue("room") + `"}`))
+	})
+	mux.HandleFunc("PUT /_matrix/client/v3/rooms/{room}/send/{type}/{txn}", func(w http.ResponseWriter, r *http.Request) {
+		var content map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&content)
+		eventID := "$" + r.PathValue("txn")
+		s.sent <- sentEvent{roomID: r.PathValue("room"), eventType: r.PathValue("type"), content: content, eventID: eventID}
+		_, _ = w.Write([]byte(`{"event_id":"` + eventID + `"}`))
+	})
+
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		mux.ServeHTTP(w, r)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func syncBatch(nextBatch string, invites []string, events map[string][]*event) *syncResponse {
+	var res syncResponse
+	res.NextBatch = nextBatch
+	res.Rooms.Invite = make(map[string]json.RawMessage)
+	for _, roomID := range invites {
+		res.Rooms.Invite[roomID] = json.RawMessage(`{}`)
+	}
+	res.Rooms.Join = make(map[string]struct {
+		Timeline struct {
+			Events []*event `json:"events"`
+		} `json:"timeline"`
+	})
+	for roomID, evs := range events {
+		room := res.Rooms.Join[roomID]
+		room.Timeline.Events = evs
+		res.Rooms.Join[roomID] = room
+	}
+	return &res
+}
+
+func textEvent(id, sender, body string) *event {
+	return &event{
+		Type:    eventTypeMessage,
+		EventID: id,
+		Sender:  sender,
+		Content: messageContent{MsgType: msgTypeText, Body: body},
+	}
+}
+
+func (s *fakeHomeserver) next(t *testing.T) sentEvent {
+	t.Helper()
+	select {
+	case ev := <-s.sent:
+		return ev
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timed out waiting for event")
+		return sentEvent{}
+	}
+}
+
+func TestBot(t *testing.T) {
+	const alice = "@alice:example.org"
+	server := newFakeHomeserver(t, syncBatch("s1", []string{"!ops:example.org", "!spam:example.org"}, map[string][]*event{
+		"!ops:example.org": {textEvent("$old", alice, "/sent before start")},
+	}))
+
+	prev := config.C
+	t.Cleanup(func() { config.C = prev })
+	config.C = config.Config{
+		Matrix: config.MatrixConfig{
+			Homeserver: server.URL,
+			Token:      "token",
+			Rooms:      config.Channels{{ID: "!ops:example.org"}},
+			Identities: config.Identities{{ID: alice, Name: "alice"}},
+		},
+		Prefix: "/",
+		Stamps: config.Stamps{Success: "✅", Running: "⏳"},
+	}
+
+	bot, err := NewBot(runCommand{}, zap.NewNop())
+	if err != nil {
+		t.Fatalf("NewBot() error = %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- bot.Start(ctx) }()
+
+	select {
+	case roomID := <-server.joined:
+		if roomID != "!ops:example.org" {
+			t.Errorf("joined %s, want !ops:example.org", roomID)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timed out waiting for join")
+	}
+
+	notice := textEvent("$notice", "@other:example.org", "/notice by bot")
+	notice.Content.MsgType = msgTypeNotice
+	server.batches <- syncBatch("s2", nil, map[string][]*event{
+		"!ops:example.org": {
+			// Ignored messages
+			textEvent("$own", "@devops:example.org", "/own message"),
+			notice,
+			textEvent("$noprefix", alice, "no prefix"),
+			// Executed
+			textEvent("$cmd", alice, "/deploy stg"),
+		},
+		"!random:example.org": {textEvent("$random", alice, "/other room")},
+	})
+
+	want := []sentEvent{
+		{roomID: "!ops:example.org", eventType: "m.reaction", content: map[string]any{
+			"m.relates_to": map[string]any{"rel_type": "m.annotation", "event_id": "$cmd", "key": "⏳"},
+		}},
+		{roomID: "!ops:example.org", eventType: "m.room.message", content: map[string]any{
+			"msgtype": "m.notice", "body": "Running `/deploy stg`",
+			"format": "org.matrix.custom.html", "formatted_body": "<p>Running <code>/deploy stg</code></p>",
+		}},
+		{roomID: "!ops:example.org", eventType: "m.reaction", content: map[string]any{
+			"m.relates_to": map[string]any{"rel_type": "m.annotation", "event_id": "$cmd", "key": "✅"},
+		}},
+	}
+	var runningID string
+	for i, w := range want {
+		got := server.next(t)
+		if i == 1 {
+			runningID = got.eventID
+		}
+		got.eventID = ""
+		if !reflect.DeepEqual(got, w) {
+			t.Errorf("event %d = %+v, want %+v", i, got, w)
+		}
+	}
+
+	// The running message is edited with the result
+	got := server.next(t)
+	got.eventID = ""
+	wantEdit := sentEvent{roomID: "!ops:example.org", eventType: "m.room.message", content: map[string]any{
+		"msgtype":        "m.notice",
+		"body":           "* alice: deploy stg",
+		"format":         "org.matrix.custom.html",
+		"formatted_body": "* <p>alice: deploy stg</p>",
+		"m.new_content": map[string]any{
+			"msgtype": "m.notice", "body": "alice: deploy stg",
+			"format": "org.matrix.custom.html", "formatted_body": "<p>alice: deploy stg</p>",
+		},
+		"m.relates_to": map[string]any{"rel_type": "m.replace", "event_id": runningID},
+	}}
+	if !reflect.DeepEqual(got, wantEdit) {
+		t.Errorf("edit = %+v, want %+v", got, wantEdit)
+	}
+
+	if err := bot.(domain.HealthReporter).Healthy(); err != nil {
+		t.Errorf("Healthy() = %v, want nil", err)
+	}
+
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Start() = %v, want nil on cancellation", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Start() did not return on cancellation")
+	}
+	select {
+	case ev := <-server.sent:
+		t.Errorf("unexpected event %+v", ev)
+	case roomID := <-server.joined:
+		t.Errorf("unexpected join to %s", roomID)
+	default:
+	}
+}
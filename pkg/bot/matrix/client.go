@@ -0,0 +1,191 @@
+package matrix
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+const apiPath = "/_matrix/client/v3"
+
+const (
+	eventTypeMessage  = "m.room.message"
+	eventTypeReaction = "m.reaction"
+
+	msgTypeText   = "m.text"
+	msgTypeNotice = "m.notice"
+
+	formatHTML = "org.matrix.custom.html"
+
+	relTypeThread     = "m.thread"
+	relTypeReplace    = "m.replace"
+	relTypeAnnotation = "m.annotation"
+)
+
+// syncFilter only receives messages in the joined rooms, and omits everything else the bot does not use.
+const syncFilter = `{"presence":{"types":[]},"account_data":{"types":[]},"room":{"account_data":{"types":[]},"ephemeral":{"types":[]},"state":{"types":[]},"timeline":{"types":["m.room.message"]}}}`
+
+// client is a minimal Matrix client-server API client.
+type client struct {
+	homeserver string
+	token      string
+	http       *http.Client
+	// txnID is incremented to generate unique transaction IDs
+	txnID atomic.Int64
+}
+
+type event struct {
+	Type    string         `json:"type"`
+	EventID string         `json:"event_id"`
+	Sender  string         `json:"sender"`
+	Content messageContent `json:"content"`
+}
+
+type messageContent struct {
+	MsgType string `json:"msgtype,omitempty"`
+	Body    string `json:"body,omitempty"`
+	// Format and FormattedBody are the HTML representation of the body
+	Format        string          `json:"format,omitempty"`
+	FormattedBody string          `json:"formatted_body,omitempty"`
+	NewContent    *messageContent `json:"m.new_content,omitempty"`
+	RelatesTo     *relatesTo      `json:"m.relates_to,omitempty"`
+}
+
+type relatesTo struct {
+	RelType string `json:"rel_type,omitempty"`
+	EventID string `json:"event_id,omitempty"`
+	// Key is the reaction key, for annotations
+	Key string `json:"key,omitempty"`
+	// IsFallingBack and InReplyTo are for clients without thread support
+	IsFallingBack bool       `json:"is_falling_back,omitempty"`
+	InReplyTo     *inReplyTo `json:"m.in_reply_to,omitempty"`
+}
+
+type inReplyTo struct {
+	EventID string `json:"event_id"`
+}
+
+type reactionContent struct {
+	RelatesTo *relatesTo `json:"m.relates_to"`
+}
+
+type syncResponse struct {
+	NextBatch string `json:"next_batch"`
+	Rooms     struct {
+		Join map[string]struct {
+			Timeline struct {
+				Events []*event `json:"events"`
+			} `json:"timeline"`
+		} `json:"join"`
+		Invite map[string]json.RawMessage `json:"invite"`
+	} `json:"rooms"`
+}
+
+func newClient(homeserver, token string) *client {
+	c := &client{
+		homeserver: strings.TrimSuffix(homeserver, "/"),
+		token:      token,
+		http:       http.DefaultClient,
+	}
+	c.txnID.Store(time.Now().UnixMilli()) // Avoid reusing transaction IDs across restarts
+	return c
+}
+
+func (c *client) do(ctx context.Context, method string, path string, query url.Values, body any, out any) error {
+	var reqBody io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("marshaling request body: %w", err)
+		}
+		reqBody = bytes.NewReader(b)
+	}
+
+	u := c.homeserver + apiPath + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, reqBody)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		b, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("%s %s: unexpected status %d: %s", method, path, resp.StatusCode, string(b))
+	}
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return fmt.Errorf("decoding response body: %w", err)
+		}
+	}
+	return nil
+}
+
+// whoami returns the user ID of the access token owner.
+func (c *client) whoami(ctx context.Context) (string, error) {
+	var res struct {
+		UserID string `json:"user_id"`
+	}
+	err := c.do(ctx, http.MethodGet, "/account/whoami", nil, nil, &res)
+	if err != nil {
+		return "", err
+	}
+	return res.UserID, nil
+}
+
+// sync receives events since the given batch token, waiting up to timeout for new events.
+func (c *client) sync(ctx context.Context, since string, timeout time.Duration) (*syncResponse, error) {
+	query := url.Values{
+		"filter":  []string{syncFilter},
+		"timeout": []string{strconv.FormatInt(timeout.Milliseconds(), 10)},
+	}
+	if since != "" {
+		query.Set("since", since)
+	}
+	var res syncResponse
+	err := c.do(ctx, http.MethodGet, "/sync", query, nil, &res)
+	if err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+func (c *client) joinRoom(ctx context.Context, roomID string) error {
+	return c.do(ctx, http.MethodPost, "/rooms/"+url.PathEscape(roomID)+"/join", nil, struct{}{}, nil)
+}
+
+// newTxnID returns a new transaction ID, which should be reused on retries for idempotency.
+func (c *client) newTxnID() string {
+	return "devopsbot." + strconv.FormatInt(c.txnID.Add(1), 10)
+}
+
+// sendEvent sends the event to the room, and returns the event ID.
+func (c *client) sendEvent(ctx context.Context, roomID string, eventType string, txnID string, content any) (string, error) {
+	var res struct {
+		EventID string `json:"event_id"`
+	}
+	path := "/rooms/" + url.PathEscape(roomID) + "/send/" + url.PathEscape(eventType) + "/" + url.PathEscape(txnID)
+	err := c.do(ctx, http.MethodPut, path, nil, content, &res)
+	if err != nil {
+		return "", err
+	}
+	return res.EventID, nil
+}
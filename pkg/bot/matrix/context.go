@@ -0,0 +1,223 @@
+package matrix
+
+import (
+	"cmp"
+	"context"
+
+	"go.uber.org/zap"
+
+	"github.com/traPtitech/DevOpsBot/pkg/config"
+	"github.com/traPtitech/DevOpsBot/pkg/domain"
+	"github.com/traPtitech/DevOpsBot/pkg/utils"
+)
+
+// messageLimit is the reply character limit.
+// Events are limited to 65536 bytes, and edits contain the message body twice.
+const messageLimit = 10000
+
+type matrixContext struct {
+	context.Context
+
+	client *client
+	logger *zap.Logger
+
+	// roomID and event are the command message
+	roomID       string
+	event        *event
+	channel      *domain.Channel
+	command      string
+	args         []string
+	replyOptions domain.ReplyOptions
+	// status is shared between the derived contexts, to edit the running message with the result
+	status *statusMessage
+}
+
+// statusMessage is the reply posted on ReplyRunning, which is edited by the following replies.
+type statusMessage struct {
+	eventID string
+}
+
+func (ctx *matrixContext) Platform() string {
+	return "matrix"
+}
+
+func (ctx *matrixContext) Channel() *domain.Channel {
+	return ctx.channel
+}
+
+func (ctx *matrixContext) Executor() string {
+	return config.C.ResolveUser("matrix", ctx.event.Sender)
+}
+
+func (ctx *matrixContext) Args() []string {
+	return ctx.args
+}
+
+func (ctx *matrixContext) ShiftArgs() domain.Context {
+	newCtx := *ctx
+	newCtx.args = newCtx.args[1:]
+	return &newCtx
+}
+
+func (ctx *matrixContext) WithArgs(args []string) domain.Context {
+	newCtx := *ctx
+	newCtx.args = args
+	return &newCtx
+}
+
+func (ctx *matrixContext) ReplyOptions() domain.ReplyOptions {
+	return ctx.replyOptions
+}
+
+func (ctx *matrixContext) WithReplyOptions(opts domain.ReplyOptions) domain.Context {
+	newCtx := *ctx
+	newCtx.replyOptions = opts
+	return &newCtx
+}
+
+func (ctx *matrixContext) L() *zap.Logger {
+	return ctx.logger.With(
+		zap.String("executor", ctx.Executor()),
+		zap.String("command", ctx.command),
+	)
+}
+
+func (ctx *matrixContext) MessageLimit() int {
+	return messageLimit
+}
+
+// StampNames returns the reaction emojis, "matrix.stamps" config taking precedence over "stamps" config.
+func (ctx *matrixContext) StampNames() *domain.StampNames {
+	return &domain.StampNames{
+		BadCommand: cmp.Or(config.C.Matrix.Stamps.BadCommand, config.C.Stamps.BadCommand),
+		Forbid:     cmp.Or(config.C.Matrix.Stamps.Forbid, config.C.Stamps.Forbid),
+		Success:    cmp.Or(config.C.Matrix.Stamps.Success, config.C.Stamps.Success),
+		Failure:    cmp.Or(config.C.Matrix.Stamps.Failure, config.C.Stamps.Failure),
+		Running:    cmp.Or(config.C.Matrix.Stamps.Running, config.C.Stamps.Running),
+	}
+}
+
+func (ctx *matrixContext) sendMatrixEvent(roomID string, eventType string, content any) (eventID string, err error) {
+	c := ctx.client
+	txnID := c.newTxnID()
+	err = utils.WithRetry(ctx, 10, func(ctx context.Context) error {
+		eventID, err = c.sendEvent(ctx, roomID, eventType, txnID, content)
+		return err
+	})
+	return eventID, err
+}
+
+func (ctx *matrixContext) pushMatrixReaction(key string) error {
+	_, err := ctx.sendMatrixEvent(ctx.roomID, eventTypeReaction, &reactionContent{
+		RelatesTo: &relatesTo{
+			RelType: relTypeAnnotation,
+			EventID: ctx.event.EventID,
+			Key:     key,
+		},
+	})
+	return err
+}
+
+// threadRoot returns the thread root event ID to reply in, if any.
+func (ctx *matrixContext) threadRoot() string {
+	if r := ctx.event.Content.RelatesTo; r != nil && r.RelType == relTypeThread {
+		return r.EventID // Always reply in the thread, if the command was sent in a thread
+	}
+	if ctx.replyOptions.Thread {
+		return ctx.event.EventID
+	}
+	return ""
+}
+
+// noticeContent renders the message in markdown as the body, and in HTML as the formatted body.
+func (ctx *matrixContext) noticeContent(message []domain.Block) *messageContent {
+	stamps := ctx.StampNames()
+	return &messageContent{
+		MsgType:       msgTypeNotice,
+		Body:          domain.Markdown(message, &domain.MarkdownStyle{Stamps: stamps, Status: func(name string) string { return name }}),
+		Format:        formatHTML,
+		FormattedBody: domain.HTMLWithStamps(message, stamps),
+	}
+}
+
+func (ctx *matrixContext) reply(content *messageContent) (eventID string, err error) {
+	if root := ctx.threadRoot(); root != "" {
+		content.RelatesTo = &relatesTo{
+			RelType:       relTypeThread,
+			EventID:       root,
+			IsFallingBack: true,
+			InReplyTo:     &inReplyTo{EventID: ctx.event.EventID},
+		}
+	}
+	return ctx.sendMatrixEvent(ctx.roomID, eventTypeMessage, content)
+}
+
+// edit replaces the content of the message sent by the bot.
+func (ctx *matrixContext) edit(eventID string, newContent *messageContent) error {
+	_, err := ctx.sendMatrixEvent(ctx.roomID, eventTypeMessage, &messageContent{
+		MsgType: msgTypeNotice,
+		// Fallback for clients without edit support
+		Body:          "* " + newContent.Body,
+		Format:        formatHTML,
+		FormattedBody: "* " + newContent.FormattedBody,
+		NewContent:    newContent,
+		RelatesTo: &relatesTo{
+			RelType: relTypeReplace,
+			EventID: eventID,
+		},
+	})
+	return err
+}
+
+// replyWithStamp reacts to the command message, and replies with the message.
+// Once the running message is posted, the following replies edit it instead of posting new messages.
+func (ctx *matrixContext) replyWithStamp(label string, stamp string, running bool, message ...domain.Block) error {
+	if stamp != "" {
+		err := ctx.pushMatrixReaction(stamp)
+		if err != nil {
+			// Still post the reply, which carries the result
+			ctx.L().Error("failed to add reaction", zap.String("key", stamp), zap.Error(err))
+		}
+	}
+
+	content := ctx.noticeContent(message)
+	if content.Body == "" {
+		if ctx.status.eventID == "" && !running {
+			return nil
+		}
+		content = ctx.noticeContent([]domain.Block{
+			domain.Paragraph{domain.Text(label + " "), domain.Code(ctx.channel.Prefix + ctx.command)},
+		})
+	}
+	if ctx.status.eventID != "" {
+		return ctx.edit(ctx.status.eventID, content)
+	}
+	eventID, err := ctx.reply(content)
+	if err != nil {
+		return err
+	}
+	if running {
+		ctx.status.eventID = eventID
+	}
+	return nil
+}
+
+func (ctx *matrixContext) ReplyBad(message ...domain.Block) error {
+	return ctx.replyWithStamp("Bad command", ctx.StampNames().BadCommand, false, message...)
+}
+
+func (ctx *matrixContext) ReplyForbid(message ...domain.Block) error {
+	return ctx.replyWithStamp("Forbidden", ctx.StampNames().Forbid, false, message...)
+}
+
+func (ctx *matrixContext) ReplySuccess(message ...domain.Block) error {
+	return ctx.replyWithStamp("Succeeded", ctx.StampNames().Success, false, message...)
+}
+
+func (ctx *matrixContext) ReplyFailure(message ...domain.Block) error {
+	return ctx.replyWithStamp("Failed", ctx.StampNames().Failure, false, message...)
+}
+
+func (ctx *matrixContext) ReplyRunning(message ...domain.Block) error {
+	return ctx.replyWithStamp("Running", ctx.StampNames().Running, true, message...)
+}
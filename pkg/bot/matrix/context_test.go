@@ -0,0 +1,45 @@
+package matrix
+
+import (
+	"testing"
+
+	"github.com/traPtitech/DevOpsBot/pkg/config"
+	"github.com/traPtitech/DevOpsBot/pkg/domain"
+)
+
+func TestStampNames(t *testing.T) {
+	prev := config.C
+	t.Cleanup(func() { config.C = prev })
+	config.C = config.Config{
+		Stamps: config.Stamps{Success: "white_check_mark", Failure: "x"},
+		Matrix: config.MatrixConfig{Stamps: config.Stamps{Success: "✅", Running: "⏳"}},
+	}
+	ctx := &matrixContext{}
+
+	got := *ctx.StampNames()
+	want := domain.StampNames{Success: "✅", Failure: "x", Running: "⏳"}
+	if got != want {
+		t.Errorf("StampNames() = %+v, want %+v", got, want)
+	}
+}
+
+func TestNoticeContent(t *testing.T) {
+	prev := config.C
+	t.Cleanup(func() { config.C = prev })
+	config.C = config.Config{Matrix: config.MatrixConfig{Stamps: config.Stamps{Success: "✅"}}}
+	ctx := &matrixContext{}
+
+	got := ctx.noticeContent([]domain.Block{
+		domain.Paragraph{domain.Status(domain.StatusSuccess), domain.Text(" a < b")},
+		domain.CodeBlock("out\n"),
+	})
+	want := &messageContent{
+		MsgType:       "m.notice",
+		Body:          "✅ a < b\n\n```\nout\n```",
+		Format:        "org.matrix.custom.html",
+		FormattedBody: "<p>✅ a &lt; b</p>\n<pre><code>out</code></pre>",
+	}
+	if *got != *want {
+		t.Errorf("noticeContent() = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,275 @@
+package mattermost
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/kballard/go-shellquote"
+	"go.uber.org/zap"
+
+	"github.com/traPtitech/DevOpsBot/pkg/config"
+	"github.com/traPtitech/DevOpsBot/pkg/domain"
+)
+
+const (
+	// readTimeout is the duration to consider the connection dead without any messages or pings.
+	// Mattermost sends pings every 30 seconds.
+	readTimeout = 90 * time.Second
+	// writeTimeout is the timeout to write control messages.
+	writeTimeout = 5 * time.Second
+
+	initialBackoff = 1 * time.Second
+	maxBackoff     = 60 * time.Second
+
+	channelTypeDirect = "D"
+)
+
+var _ domain.HealthReporter = (*mattermostBot)(nil)
+
+type mattermostBot struct {
+	client  *client
+	rootCmd domain.Command
+	logger  *zap.Logger
+	// userID is the user ID of this bot, to ignore its own posts
+	userID string
+
+	mu        sync.Mutex
+	connected bool
+	lastErr   error
+}
+
+func NewBot(rootCmd domain.Command, logger *zap.Logger) (domain.Bot, error) {
+	if config.C.Mattermost.Origin == "" || config.C.Mattermost.Token == "" {
+		return nil, fmt.Errorf("mattermost.origin and mattermost.token need to be set")
+	}
+	c := newClient(config.C.Mattermost.Origin, config.C.Mattermost.Token)
+
+	me, err := c.getUser(context.Background(), "me")
+	if err != nil {
+		return nil, fmt.Errorf("getting bot user: %w", err)
+	}
+
+	return &mattermostBot{
+		client:  c,
+		rootCmd: rootCmd,
+		logger:  logger,
+		userID:  me.ID,
+	}, nil
+}
+
+// Start connects to the WebSocket API, and reconnects with backoff until ctx is cancelled.
+func (b *mattermostBot) Start(ctx context.Context) error {
+	backoff := initialBackoff
+	for {
+		connected, err := b.connect(ctx)
+		if ctx.Err() != nil {
+			return nil // Shutting down
+		}
+		b.setConnected(false, err)
+
+		if connected {
+			backoff = initialBackoff
+			b.logger.Warn("disconnected from Mattermost, reconnecting", zap.Error(err), zap.Duration("backoff", backoff))
+		} else {
+			b.logger.Warn("failed to connect to Mattermost, retrying", zap.Error(err), zap.Duration("backoff", backoff))
+		}
+
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return nil
+		}
+		if !connected {
+			backoff = min(backoff*2, maxBackoff)
+		}
+	}
+}
+
+func (b *mattermostBot) Healthy() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.connected {
+		return nil
+	}
+	if b.lastErr != nil {
+		return fmt.Errorf("not connected to Mattermost: %w", b.lastErr)
+	}
+	return fmt.Errorf("not connected to Mattermost")
+}
+
+func (b *mattermostBot) setConnected(connected bool, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.connected = connected
+	b.lastErr = err
+}
+
+// connect connects to the WebSocket API and processes events until disconnected.
+// connected reports whether the connection has been established.
+func (b *mattermostBot) connect(ctx context.Context) (connected bool, err error) {
+	header := http.Header{"Authorization": []string{"Bearer " + b.client.token}}
+	conn, _, err := websocket.DefaultDialer.DialContext(ctx, b.client.websocketURL(), header)
+	if err != nil {
+		return false, fmt.Errorf("dialing websocket: %w", err)
+	}
+	defer conn.Close()
+
+	// Close the connection on shutdown, to stop reading
+	stop := context.AfterFunc(ctx, func() {
+		_ = conn.WriteControl(websocket.CloseMessage,
+			websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""),
+			time.Now().Add(writeTimeout))
+		_ = conn.Close()
+	})
+	defer stop()
+
+	// Detect half-open connections
+	extendDeadline := func() error {
+		return conn.SetReadDeadline(time.Now().Add(readTimeout))
+	}
+	if err := extendDeadline(); err != nil {
+		return true, err
+	}
+	conn.SetPingHandler(func(data string) error {
+		if err := extendDeadline(); err != nil {
+			return err
+		}
+		return conn.WriteControl(websocket.PongMessage, []byte(data), time.Now().Add(writeTimeout))
+	})
+
+	b.setConnected(true, nil)
+	b.logger.Info("connected to Mattermost")
+	for {
+		_, p, err := conn.ReadMessage()
+		if err != nil {
+			return true, fmt.Errorf("reading message: %w", err)
+		}
+		if err := extendDeadline(); err != nil {
+			return true, err
+		}
+		b.handleEvent(p)
+	}
+}
+
+type wsEvent struct {
+	Event string          `json:"event"`
+	Data  json.RawMessage `json:"data"`
+}
+
+type postedData struct {
+	ChannelType string `json:"channel_type"`
+	// Post is the JSON-encoded post
+	Post string `json:"post"`
+}
+
+func (b *mattermostBot) handleEvent(p []byte) {
+	var ev wsEvent
+	if err := json.Unmarshal(p, &ev); err != nil {
+		b.logger.Error("unexpected message format", zap.Error(err))
+		return
+	}
+	if ev.Event != "posted" {
+		return
+	}
+
+	var data postedData
+	if err := json.Unmarshal(ev.Data, &data); err != nil {
+		b.logger.Error("unexpected posted event format", zap.Error(err))
+		return
+	}
+	var po post
+	if err := json.Unmarshal([]byte(data.Post), &po); err != nil {
+		b.logger.Error("unexpected post format", zap.Error(err))
+		return
+	}
+	b.postReceived(&po, data.ChannelType)
+}
+
+func (b *mattermostBot) postReceived(p *post, channelType string) {
+	// Validate command execution context
+	if p.UserID == b.userID {
+		return // Ignore own posts
+	}
+
+	var channel *domain.Channel
+	var commandText string
+	if channelType == channelTypeDirect {
+		if !config.C.DM.Enabled {
+			return // Ignore direct messages
+		}
+		// Command prefix is optional in direct messages
+		channel = &domain.Channel{
+			ID:     p.ChannelID,
+			Prefix: config.C.Prefix,
+			DM:     true,
+		}
+		commandText = strings.TrimPrefix(p.Message, config.C.Prefix)
+	} else {
+		channelConfig, ok := config.C.Mattermost.Channels.Find(p.ChannelID)
+		if !ok {
+			return // Ignore messages not from the specified channels
+		}
+		prefix := channelConfig.CommandPrefix()
+		if !strings.HasPrefix(p.Message, prefix) {
+			return // Command prefix does not match
+		}
+		channel = &domain.Channel{
+			ID:       channelConfig.ID,
+			Prefix:   prefix,
+			Commands: channelConfig.Commands,
+		}
+		commandText = strings.TrimPrefix(p.Message, prefix)
+	}
+
+	go func() {
+		err := b.executeCommand(p, channel, commandText)
+		if err != nil {
+			b.logger.Error("failed to execute command", zap.Error(err))
+		}
+	}()
+}
+
+func (b *mattermostBot) executeCommand(p *post, channel *domain.Channel, commandText string) error {
+	u, err := b.client.getUser(context.Background(), p.UserID)
+	if err != nil {
+		return fmt.Errorf("getting user: %w", err)
+	}
+	if u.IsBot {
+		return nil // Ignore bots
+	}
+
+	// Prepare command args
+	ctx := &mattermostContext{
+		Context:   context.Background(),
+		client:    b.client,
+		logger:    b.logger,
+		botUserID: b.userID,
+		post:      p,
+		userID:    p.UserID,
+		username:  u.Username,
+		command:   commandText,
+		channel:   channel,
+		args:      nil,
+		replyOptions: domain.ReplyOptions{
+			Thread:    config.C.Thread.Enabled,
+			Broadcast: config.C.Thread.Broadcast,
+		},
+	}
+	args, err := shellquote.Split(commandText)
+	if err != nil {
+		return ctx.ReplyBad(domain.Textf("failed to parse arguments: %v", err))
+	}
+	if len(args) == 0 {
+		return nil
+	}
+	ctx.args = args
+
+	// Execute
+	return b.rootCmd.Execute(ctx)
+}
@@ -0,0 +1,258 @@
+package mattermost
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+
+	"github.com/traPtitech/DevOpsBot/pkg/config"
+	"github.com/traPtitech/DevOpsBot/pkg/domain"
+)
+
+// echoCommand replies with the executor and the arguments.
+type echoCommand struct{}
+
+func (echoCommand) Execute(ctx domain.Context) error {
+	return ctx.ReplySuccess(domain.Textf("%s: %s", ctx.Executor(), strings.Join(ctx.Args(), " ")))
+}
+func (echoCommand) HasSubcommands() bool                                { return false }
+func (echoCommand) GetSubcommand(string) (domain.Command, bool)         { return nil, false }
+func (echoCommand) HelpMessage(domain.Context, bool) []*domain.ListItem { return nil }
+func (echoCommand) List(string) []*domain.CommandInfo                   { return nil }
+
+// fakeServer emulates the parts of Mattermost API used by the bot.
+type fakeServer struct {
+	*httptest.Server
+	events    chan string
+	posts     chan post
+	reactions chan reaction
+}
+
+func newFakeServer(t *testing.T) *fakeServer {
+	t.Helper()
+	s := &fakeServer{
+		events:    make(chan string, 10),
+		posts:     make(chan post, 10),
+		reactions: make(chan reaction, 10),
+	}
+	users := map[string]user{
+		"me":       {ID: "bot-id", Username: "devops", IsBot: true},
+		"alice-id": {ID: "alice-id", Username: "alice"},
+		"other-id": {ID: "other-id", Username: "other-bot", IsBot: true},
+	}
+	upgrader := websocket.Upgrader{}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v4/users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		u, ok := users[r.PathValue("id")]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(u)
+	})
+	mux.HandleFunc("POST /api/v4/posts", func(w http.ResponseWriter, r *http.Request) {
+		var p post
+		_ = json.NewDecoder(r.Body).Decode(&p)
+		p.ID = "reply-id"
+		s.posts <- p
+		_ = json.NewEncoder(w).Encode(p)
+	})
+	mux.HandleFunc("POST /api/v4/reactions", func(w http.ResponseWriter, r *http.Request) {
+		var re reaction
+		_ = json.NewDecoder(r.Body).Decode(&re)
+		s.reactions <- re
+		_ = json.NewEncoder(w).Encode(re)
+	})
+	mux.HandleFunc("GET /api/v4/websocket", func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for ev := range s.events {
+			if err := conn.WriteMessage(websocket.TextMessage, []byte(ev)); err != nil {
+				return
+			}
+		}
+	})
+
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		mux.ServeHTTP(w, r)
+	}))
+	t.Cleanup(func() {
+		close(s.events)
+		s.Close()
+	})
+	return s
+}
+
+// post sends a posted event.
+func (s *fakeServer) post(t *testing.T, channelType string, p post) {
+	t.Helper()
+	b, err := json.Marshal(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ev, err := json.Marshal(map[string]any{
+		"event": "posted",
+		"data": map[string]any{
+			"channel_type": channelType,
+			"post":         string(b),
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.events <- string(ev)
+}
+
+func TestBot(t *testing.T) {
+	server := newFakeServer(t)
+
+	prev := config.C
+	t.Cleanup(func() { config.C = prev })
+	config.C = config.Config{
+		Mattermost: config.MattermostConfig{
+			Origin:       server.URL,
+			Token:        "token",
+			Channels:     config.Channels{{ID: "ops"}},
+			MessageLimit: 16000,
+		},
+		Prefix: "/",
+		Thread: config.ThreadConfig{Enabled: true},
+		Stamps: config.Stamps{Success: "white_check_mark"},
+	}
+
+	bot, err := NewBot(echoCommand{}, zap.NewNop())
+	if err != nil {
+		t.Fatalf("NewBot() error = %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- bot.Start(ctx) }()
+
+	// Ignored posts
+	server.post(t, "O", post{ID: "p1", UserID: "bot-id", ChannelID: "ops", Message: "/own post"})
+	server.post(t, "O", post{ID: "p2", UserID: "other-id", ChannelID: "ops", Message: "/other bot"})
+	server.post(t, "O", post{ID: "p3", UserID: "alice-id", ChannelID: "random", Message: "/other channel"})
+	server.post(t, "O", post{ID: "p4", UserID: "alice-id", ChannelID: "ops", Message: "no prefix"})
+	server.post(t, "D", post{ID: "p5", UserID: "alice-id", ChannelID: "dm", Message: "dm disabled"})
+	// Executed
+	server.post(t, "O", post{ID: "p6", UserID: "alice-id", ChannelID: "ops", Message: "/deploy stg"})
+
+	select {
+	case re := <-server.reactions:
+		want := reaction{UserID: "bot-id", PostID: "p6", EmojiName: "white_check_mark"}
+		if re != want {
+			t.Errorf("reaction = %+v, want %+v", re, want)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timed out waiting for reaction")
+	}
+	select {
+	case p := <-server.posts:
+		want := post{ID: "reply-id", ChannelID: "ops", RootID: "p6", Message: "alice-id: deploy stg"}
+		if p != want {
+			t.Errorf("reply = %+v, want %+v", p, want)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timed out waiting for reply")
+	}
+	if err := bot.(domain.HealthReporter).Healthy(); err != nil {
+		t.Errorf("Healthy() = %v, want nil", err)
+	}
+
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Start() = %v, want nil on cancellation", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Start() did not return on cancellation")
+	}
+	select {
+	case p := <-server.posts:
+		t.Errorf("unexpected post %+v", p)
+	case re := <-server.reactions:
+		t.Errorf("unexpected reaction %+v", re)
+	default:
+	}
+}
+
+// gatedCommand forbids "secret", and runs other commands.
+type gatedCommand struct{ echoCommand }
+
+func (gatedCommand) Execute(ctx domain.Context) error {
+	if ctx.Args()[0] == "secret" {
+		return ctx.ReplyForbid()
+	}
+	_ = ctx.ReplyRunning()
+	return ctx.ReplySuccess(domain.Textf("done"))
+}
+
+func TestBot_DMNotice(t *testing.T) {
+	server := newFakeServer(t)
+
+	prev := config.C
+	t.Cleanup(func() { config.C = prev })
+	config.C = config.Config{
+		Mattermost: config.MattermostConfig{
+			Origin:       server.URL,
+			Token:        "token",
+			Channels:     config.Channels{{ID: "ops"}},
+			MessageLimit: 16000,
+		},
+		Prefix: "/",
+		DM:     config.DMConfig{Enabled: true, Mirror: true},
+		Stamps: config.Stamps{Forbid: "no_entry"},
+	}
+
+	bot, err := NewBot(gatedCommand{}, zap.NewNop())
+	if err != nil {
+		t.Fatalf("NewBot() error = %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go func() { _ = bot.Start(ctx) }()
+
+	// Forbidden commands are not mirrored
+	server.post(t, "D", post{ID: "p1", UserID: "alice-id", ChannelID: "dm", Message: "secret"})
+	select {
+	case re := <-server.reactions:
+		if re.PostID != "p1" || re.EmojiName != "no_entry" {
+			t.Errorf("reaction = %+v, want no_entry on p1", re)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timed out waiting for reaction")
+	}
+
+	// Executed commands are mirrored before the reply
+	server.post(t, "D", post{ID: "p2", UserID: "alice-id", ChannelID: "dm", Message: "deploy"})
+	wants := []post{
+		{ID: "reply-id", ChannelID: "ops", Message: "@alice executed `/deploy` in direct message"},
+		{ID: "reply-id", ChannelID: "dm", Message: "done"},
+	}
+	for _, want := range wants {
+		select {
+		case p := <-server.posts:
+			if p != want {
+				t.Errorf("post = %+v, want %+v", p, want)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for post %+v", want)
+		}
+	}
+}
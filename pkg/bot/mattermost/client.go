@@ -0,0 +1,113 @@
+package mattermost
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const apiPath = "/api/v4"
+
+// client is a minimal Mattermost REST API v4 client.
+type client struct {
+	origin string
+	token  string
+	http   *http.Client
+}
+
+type post struct {
+	ID        string `json:"id,omitempty"`
+	UserID    string `json:"user_id,omitempty"`
+	ChannelID string `json:"channel_id"`
+	RootID    string `json:"root_id,omitempty"`
+	Message   string `json:"message"`
+}
+
+type user struct {
+	ID       string `json:"id"`
+	Username string `json:"username"`
+	IsBot    bool   `json:"is_bot"`
+}
+
+type reaction struct {
+	UserID    string `json:"user_id"`
+	PostID    string `json:"post_id"`
+	EmojiName string `json:"emoji_name"`
+}
+
+func newClient(origin, token string) *client {
+	return &client{
+		origin: strings.TrimSuffix(origin, "/"),
+		token:  token,
+		http:   http.DefaultClient,
+	}
+}
+
+func (c *client) do(ctx context.Context, method string, path string, body any, out any) error {
+	var reqBody io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("marshaling request body: %w", err)
+		}
+		reqBody = bytes.NewReader(b)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.origin+apiPath+path, reqBody)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		b, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("%s %s: unexpected status %d: %s", method, path, resp.StatusCode, string(b))
+	}
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return fmt.Errorf("decoding response body: %w", err)
+		}
+	}
+	return nil
+}
+
+func (c *client) getUser(ctx context.Context, userID string) (*user, error) {
+	var u user
+	err := c.do(ctx, http.MethodGet, "/users/"+url.PathEscape(userID), nil, &u)
+	if err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
+
+func (c *client) createPost(ctx context.Context, p *post) (*post, error) {
+	var created post
+	err := c.do(ctx, http.MethodPost, "/posts", p, &created)
+	if err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+func (c *client) addReaction(ctx context.Context, r *reaction) error {
+	return c.do(ctx, http.MethodPost, "/reactions", r, nil)
+}
+
+// websocketURL returns the URL of the WebSocket API.
+func (c *client) websocketURL() string {
+	origin := strings.Replace(c.origin, "http", "ws", 1) // http:// -> ws://, https:// -> wss://
+	return origin + apiPath + "/websocket"
+}
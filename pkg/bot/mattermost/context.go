@@ -0,0 +1,180 @@
+package mattermost
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+
+	"go.uber.org/zap"
+
+	"github.com/traPtitech/DevOpsBot/pkg/config"
+	"github.com/traPtitech/DevOpsBot/pkg/domain"
+	"github.com/traPtitech/DevOpsBot/pkg/utils"
+)
+
+type mattermostContext struct {
+	context.Context
+
+	client    *client
+	logger    *zap.Logger
+	botUserID string
+
+	// post is the command message
+	post         *post
+	userID       string
+	username     string
+	command      string
+	channel      *domain.Channel
+	args         []string
+	replyOptions domain.ReplyOptions
+}
+
+func (ctx *mattermostContext) Platform() string {
+	return "mattermost"
+}
+
+func (ctx *mattermostContext) Channel() *domain.Channel {
+	return ctx.channel
+}
+
+func (ctx *mattermostContext) Executor() string {
+	return config.C.ResolveUser("mattermost", ctx.userID)
+}
+
+func (ctx *mattermostContext) Args() []string {
+	return ctx.args
+}
+
+func (ctx *mattermostContext) ShiftArgs() domain.Context {
+	newCtx := *ctx
+	newCtx.args = newCtx.args[1:]
+	return &newCtx
+}
+
+func (ctx *mattermostContext) WithArgs(args []string) domain.Context {
+	newCtx := *ctx
+	newCtx.args = args
+	return &newCtx
+}
+
+func (ctx *mattermostContext) ReplyOptions() domain.ReplyOptions {
+	return ctx.replyOptions
+}
+
+func (ctx *mattermostContext) WithReplyOptions(opts domain.ReplyOptions) domain.Context {
+	newCtx := *ctx
+	newCtx.replyOptions = opts
+	return &newCtx
+}
+
+func (ctx *mattermostContext) L() *zap.Logger {
+	return ctx.logger.With(
+		zap.String("executor", ctx.Executor()),
+		zap.String("command", ctx.post.Message),
+	)
+}
+
+func (ctx *mattermostContext) MessageLimit() int {
+	return config.C.Mattermost.MessageLimit
+}
+
+// StampNames returns the reaction emojis, "mattermost.stamps" config taking precedence over "stamps" config.
+func (ctx *mattermostContext) StampNames() *domain.StampNames {
+	return &domain.StampNames{
+		BadCommand: cmp.Or(config.C.Mattermost.Stamps.BadCommand, config.C.Stamps.BadCommand),
+		Forbid:     cmp.Or(config.C.Mattermost.Stamps.Forbid, config.C.Stamps.Forbid),
+		Success:    cmp.Or(config.C.Mattermost.Stamps.Success, config.C.Stamps.Success),
+		Failure:    cmp.Or(config.C.Mattermost.Stamps.Failure, config.C.Stamps.Failure),
+		Running:    cmp.Or(config.C.Mattermost.Stamps.Running, config.C.Stamps.Running),
+	}
+}
+
+func (ctx *mattermostContext) sendMattermostMessage(p *post) error {
+	c := ctx.client
+	return utils.WithRetry(ctx, 10, func(ctx context.Context) error {
+		_, err := c.createPost(ctx, p)
+		return err
+	})
+}
+
+func (ctx *mattermostContext) pushMattermostReaction(postID string, emoji string) error {
+	c := ctx.client
+	r := &reaction{
+		UserID:    ctx.botUserID,
+		PostID:    postID,
+		EmojiName: emoji,
+	}
+	return utils.WithRetry(ctx, 10, func(ctx context.Context) error {
+		return c.addReaction(ctx, r)
+	})
+}
+
+func (ctx *mattermostContext) reply(message ...domain.Block) error {
+	p := &post{
+		ChannelID: ctx.post.ChannelID,
+		Message:   domain.Markdown(message, &domain.MarkdownStyle{Stamps: ctx.StampNames()}),
+	}
+	switch {
+	case ctx.post.RootID != "":
+		// Always reply in the thread, if the command was sent in a thread
+		p.RootID = ctx.post.RootID
+	case ctx.replyOptions.Thread:
+		p.RootID = ctx.post.ID
+	}
+	return ctx.sendMattermostMessage(p)
+}
+
+func (ctx *mattermostContext) replyWithStamp(stamp string, message ...domain.Block) error {
+	if stamp != "" {
+		err := ctx.pushMattermostReaction(ctx.post.ID, stamp)
+		if err != nil {
+			// Still post the reply, which carries the result
+			ctx.L().Error("failed to add reaction", zap.String("emoji", stamp), zap.Error(err))
+		}
+	}
+	if len(message) > 0 {
+		err := ctx.reply(message...)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (ctx *mattermostContext) ReplyBad(message ...domain.Block) error {
+	return ctx.replyWithStamp(ctx.StampNames().BadCommand, message...)
+}
+
+func (ctx *mattermostContext) ReplyForbid(message ...domain.Block) error {
+	return ctx.replyWithStamp(ctx.StampNames().Forbid, message...)
+}
+
+func (ctx *mattermostContext) ReplySuccess(message ...domain.Block) error {
+	return ctx.replyWithStamp(ctx.StampNames().Success, message...)
+}
+
+func (ctx *mattermostContext) ReplyFailure(message ...domain.Block) error {
+	return ctx.replyWithStamp(ctx.StampNames().Failure, message...)
+}
+
+// postDMNotice posts an audit notice to the main channel, if executed in direct messages.
+// It is called on ReplyRunning, after the permission checks pass.
+func (ctx *mattermostContext) postDMNotice() {
+	if !ctx.channel.DM || !config.C.DM.Mirror {
+		return
+	}
+	mainChannel, ok := config.C.Mattermost.Channels.Main()
+	if !ok {
+		return
+	}
+	notice := fmt.Sprintf("@%s executed `%s%s` in direct message", ctx.username, config.C.Prefix, ctx.command)
+	err := ctx.sendMattermostMessage(&post{ChannelID: mainChannel.ID, Message: notice})
+	if err != nil {
+		ctx.L().Error("failed to post direct message audit notice", zap.Error(err))
+	}
+}
+
+func (ctx *mattermostContext) ReplyRunning(message ...domain.Block) error {
+	ctx.postDMNotice()
+	return ctx.replyWithStamp(ctx.StampNames().Running, message...)
+}
@@ -0,0 +1,24 @@
+package mattermost
+
+import (
+	"testing"
+
+	"github.com/traPtitech/DevOpsBot/pkg/config"
+	"github.com/traPtitech/DevOpsBot/pkg/domain"
+)
+
+func TestStampNames(t *testing.T) {
+	prev := config.C
+	t.Cleanup(func() { config.C = prev })
+	config.C = config.Config{
+		Stamps:     config.Stamps{Success: "white_check_mark", Failure: "x"},
+		Mattermost: config.MattermostConfig{Stamps: config.Stamps{Success: "heavy_check_mark", Running: "devops_running"}},
+	}
+	ctx := &mattermostContext{}
+
+	got := *ctx.StampNames()
+	want := domain.StampNames{Success: "heavy_check_mark", Failure: "x", Running: "devops_running"}
+	if got != want {
+		t.Errorf("StampNames() = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,179 @@
+package slack
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/samber/lo"
+	"github.com/slack-go/slack"
+
+	"github.com/traPtitech/DevOpsBot/pkg/config"
+	"github.com/traPtitech/DevOpsBot/pkg/domain"
+)
+
+const (
+	// headerTextLimit is the maximum length of header block text.
+	headerTextLimit = 150
+	// sectionTextLimit is the maximum length of section block text.
+	sectionTextLimit = 3000
+	// fieldsLimit is the maximum number of fields in a section block.
+	fieldsLimit = 10
+	// fieldTextLimit is the maximum length of each field text.
+	fieldTextLimit = 2000
+	// codeFence is the markdown code block delimiter.
+	codeFence = "```"
+)
+
+// replyContent is the content of a reply to render with Block Kit.
+type replyContent struct {
+	// title is displayed in the header block.
+	title string
+	// meta are displayed in the context block, below the header.
+	meta []string
+	// message is the reply message, displayed in the section blocks.
+	message []domain.Block
+	// color is the color of the attachment bar.
+	color string
+}
+
+// renderBlocks renders reply content to Block Kit blocks.
+//
+// The header and context blocks are returned as the message blocks,
+// and the section blocks are wrapped in a colored attachment, which Slack collapses if it is too long.
+func renderBlocks(c *replyContent) ([]slack.Block, slack.Attachment) {
+	var blocks []slack.Block
+	blocks = append(blocks, slack.NewHeaderBlock(
+		slack.NewTextBlockObject(slack.PlainTextType, truncate(c.title, headerTextLimit), true, false),
+	))
+	if len(c.meta) > 0 {
+		elements := make([]slack.MixedElement, 0, len(c.meta))
+		for _, m := range c.meta {
+			elements = append(elements, slack.NewTextBlockObject(slack.MarkdownType, m, false, false))
+		}
+		blocks = append(blocks, slack.NewContextBlock("", elements...))
+	}
+
+	var sections []slack.Block
+	for _, section := range renderSections(c.message) {
+		sections = append(sections, section)
+	}
+	attachment := slack.Attachment{
+		Color:  c.color,
+		Blocks: slack.Blocks{BlockSet: sections},
+	}
+	return blocks, attachment
+}
+
+// renderSections renders the reply message to section blocks.
+// Consecutive text blocks are combined into one section, and code blocks are split into multiple sections if they are too long.
+func renderSections(message []domain.Block) []*slack.SectionBlock {
+	var sections []*slack.SectionBlock
+	var text []string
+
+	flushText := func() {
+		for _, chunk := range splitText(strings.Join(text, "\n\n"), sectionTextLimit) {
+			if strings.TrimSpace(chunk) != "" {
+				sections = append(sections, markdownSection(chunk))
+			}
+		}
+		text = nil
+	}
+
+	for _, b := range message {
+		switch b := b.(type) {
+		case domain.Heading:
+			// Slack mrkdwn has no headings - display them in bold instead
+			text = append(text, "*"+mrkdwn(b)+"*")
+		case domain.Paragraph:
+			text = append(text, mrkdwn(b))
+		case domain.List:
+			text = append(text, strings.Join(domain.ListLines(b, "• ", mrkdwn), "\n"))
+		case domain.CodeBlock:
+			flushText()
+			code := strings.Trim(escape(string(b)), "\n")
+			if code == "" {
+				continue
+			}
+			fenceLen := 2 * (len(codeFence) + 1)
+			for _, chunk := range splitText(code, sectionTextLimit-fenceLen) {
+				sections = append(sections, markdownSection(codeFence+"\n"+chunk+"\n"+codeFence))
+			}
+		case domain.Fields:
+			flushText()
+			for _, chunk := range lo.Chunk(b, fieldsLimit) {
+				fields := make([]*slack.TextBlockObject, 0, len(chunk))
+				for _, f := range chunk {
+					fields = append(fields, slack.NewTextBlockObject(slack.MarkdownType,
+						truncate("*"+escape(f.Name)+"*\n"+mrkdwn(f.Value), fieldTextLimit), false, false))
+				}
+				sections = append(sections, slack.NewSectionBlock(nil, fields, nil))
+			}
+		}
+	}
+	flushText()
+
+	return sections
+}
+
+// mrkdwn renders the inline elements in Slack mrkdwn.
+func mrkdwn(inlines []domain.Inline) string {
+	var sb strings.Builder
+	for _, in := range inlines {
+		switch in := in.(type) {
+		case domain.Text:
+			sb.WriteString(escape(string(in)))
+		case domain.Code:
+			sb.WriteString("`" + escape(string(in)) + "`")
+		case domain.Mention:
+			sb.WriteString(mention(string(in)))
+		case domain.Status:
+			if name := stampNames().Name(domain.StatusKind(in)); name != "" {
+				sb.WriteString(":" + name + ":")
+			}
+		}
+	}
+	return sb.String()
+}
+
+// mention mentions the operator by the Slack member ID in "users" config,
+// or displays the name as is, as operator names may not be Slack member IDs.
+func mention(name string) string {
+	if u, ok := config.C.Users.Find(name); ok && u.Slack != "" {
+		return "<@" + u.Slack + ">"
+	}
+	return escape(name)
+}
+
+// escape escapes the control characters of Slack mrkdwn.
+func escape(s string) string {
+	return mrkdwnEscaper.Replace(s)
+}
+
+var mrkdwnEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// splitText splits the text into chunks of at most limit bytes, preferably at line breaks.
+func splitText(s string, limit int) []string {
+	var chunks []string
+	for len(s) > limit {
+		i := strings.LastIndexByte(s[:limit], '\n')
+		if i <= 0 {
+			// No line breaks - split at the last rune boundary
+			i = limit
+			for i > 0 && !utf8.RuneStart(s[i]) {
+				i--
+			}
+		}
+		chunks = append(chunks, s[:i])
+		s = strings.TrimPrefix(s[i:], "\n")
+	}
+	return append(chunks, s)
+}
+
+// truncate truncates the string to at most limit runes.
+func truncate(s string, limit int) string {
+	if utf8.RuneCountInString(s) <= limit {
+		return s
+	}
+	r := []rune(s)
+	return string(r[:limit-1]) + "…"
+}
@@ -0,0 +1,108 @@
+package slack
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/traPtitech/DevOpsBot/pkg/config"
+	"github.com/traPtitech/DevOpsBot/pkg/domain"
+)
+
+func TestRenderSections(t *testing.T) {
+	longLine := strings.Repeat("a", sectionTextLimit)
+
+	tests := []struct {
+		name    string
+		message []domain.Block
+		want    []string
+	}{
+		{
+			name: "text only",
+			message: []domain.Block{
+				domain.Heading{domain.Text("Usage")},
+				domain.List{{
+					Content:  []domain.Inline{domain.Code("/deploy <tag>"), domain.Text(" ("), domain.Mention("alice"), domain.Text(")")},
+					Children: []*domain.ListItem{{Content: []domain.Inline{domain.Code("/deploy stg")}}},
+				}},
+			},
+			want: []string{"*Usage*\n\n• `/deploy &lt;tag&gt;` (alice)\n  • `/deploy stg`"},
+		},
+		{
+			name: "text and code",
+			message: []domain.Block{
+				domain.Paragraph{domain.Status(domain.StatusSuccess)},
+				domain.CodeBlock("hello\n"),
+			},
+			want: []string{":white_check_mark:", "```\nhello\n```"},
+		},
+		{
+			name:    "empty code",
+			message: []domain.Block{domain.CodeBlock("\n")},
+			want:    nil,
+		},
+		{
+			name:    "long code is split into multiple fenced blocks",
+			message: []domain.Block{domain.CodeBlock("first\n" + longLine)},
+			want: []string{
+				"```\nfirst\n```",
+				"```\n" + longLine[:sectionTextLimit-8] + "\n```",
+				"```\n" + longLine[sectionTextLimit-8:] + "\n```",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prev := config.C
+			t.Cleanup(func() { config.C = prev })
+			config.C.Stamps.Success = "white_check_mark"
+
+			var got []string
+			for _, s := range renderSections(tt.message) {
+				got = append(got, s.Text.Text)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("renderSections() =\n%q\nwant\n%q", got, tt.want)
+			}
+			for _, s := range got {
+				if len(s) > sectionTextLimit {
+					t.Errorf("section length %d exceeds limit %d", len(s), sectionTextLimit)
+				}
+			}
+		})
+	}
+}
+
+func TestRenderSections_Fields(t *testing.T) {
+	fields := make(domain.Fields, 12)
+	for i := range fields {
+		fields[i] = domain.Field{Name: "Name", Value: []domain.Inline{domain.Code("value")}}
+	}
+	sections := renderSections([]domain.Block{fields})
+	if len(sections) != 2 || len(sections[0].Fields) != fieldsLimit || len(sections[1].Fields) != 2 {
+		t.Fatalf("renderSections() = %d sections, want fields split by %d", len(sections), fieldsLimit)
+	}
+	if got, want := sections[0].Fields[0].Text, "*Name*\n`value`"; got != want {
+		t.Errorf("field text = %q, want %q", got, want)
+	}
+}
+
+func TestSplitText_RuneBoundary(t *testing.T) {
+	s := strings.Repeat("あ", 5) // 3 bytes each
+	got := splitText(s, 4)
+	want := []string{"あ", "あ", "あ", "あ", "あ"}
+	if !slices.Equal(got, want) {
+		t.Errorf("splitText() = %q, want %q", got, want)
+	}
+}
+
+func TestMrkdwn_Mention(t *testing.T) {
+	prev := config.C
+	t.Cleanup(func() { config.C = prev })
+	config.C = config.Config{Users: config.Users{{Name: "toki", Slack: "U01234ABCDE"}}}
+
+	got := mrkdwn([]domain.Inline{domain.Mention("toki"), domain.Text(", "), domain.Mention("a<b")})
+	if want := "<@U01234ABCDE>, a&lt;b"; got != want {
+		t.Errorf("mrkdwn() = %q, want %q", got, want)
+	}
+}
@@ -14,6 +14,7 @@ import (
 	"log/slog"
 	"regexp"
 	"strings"
+	"time"
 )
 
 const slashPrefix = "/"
@@ -23,6 +24,7 @@ type slackBot struct {
 	sock    *socketmode.Client
 	rootCmd domain.Command
 	logger  *zap.Logger
+	history *history
 }
 
 func NewBot(rootCmd domain.Command, logger *zap.Logger) (domain.Bot, error) {
@@ -35,6 +37,7 @@ func NewBot(rootCmd domain.Command, logger *zap.Logger) (domain.Bot, error) {
 		sock:    sock,
 		rootCmd: rootCmd,
 		logger:  logger,
+		history: &history{},
 	}, nil
 }
 
@@ -47,7 +50,11 @@ func (s *slackBot) Start(ctx context.Context) error {
 			}
 		}
 	}()
-	return s.sock.RunContext(ctx)
+	err := s.sock.RunContext(ctx)
+	if ctx.Err() != nil {
+		return nil // Shutting down
+	}
+	return err
 }
 
 func (s *slackBot) handle(e socketmode.Event) error {
@@ -82,16 +89,35 @@ func (s *slackBot) handle(e socketmode.Event) error {
 			return fmt.Errorf("failed to parse slash command type")
 		}
 
-		// Acknowledge the event
-		s.sock.Ack(*e.Request, map[string]any{
-			"response_type": "in_channel",
-		})
+		// Acknowledge the event without displaying anything - replies are posted to the response URL
+		s.sock.Ack(*e.Request)
 
 		// Process the event
 		err := s.handleSlashEvent(&slashE)
 		if err != nil {
 			return fmt.Errorf("failed to process slash event: %w", err)
 		}
+
+	case socketmode.EventTypeInteractive:
+		callbackE, ok := e.Data.(slack.InteractionCallback)
+		if !ok {
+			return fmt.Errorf("failed to parse interactive type")
+		}
+
+		// Display validation errors in the submitted modal, if any
+		if errs := s.validateInteractive(&callbackE); len(errs) > 0 {
+			s.sock.Ack(*e.Request, slack.NewErrorsViewSubmissionResponse(errs))
+			return nil
+		}
+
+		// Acknowledge the event (this also closes the submitted modal, if any)
+		s.sock.Ack(*e.Request)
+
+		// Process the event
+		err := s.handleInteractive(&callbackE)
+		if err != nil {
+			return fmt.Errorf("failed to process interactive event: %w", err)
+		}
 	}
 
 	return nil
@@ -142,20 +168,39 @@ func (s *slackBot) handleEventsAPI(e *slackevents.EventsAPIEvent) error {
 		if !ok {
 			return nil // Not a valid user
 		}
-		if ev.Channel != config.C.Slack.ChannelID {
-			return nil // Ignore messages not from the specified channel
+		messageRef := slack.ItemRef{
+			Channel:   ev.Channel,
+			Timestamp: ev.TimeStamp,
 		}
-		if !strings.HasPrefix(commandText, config.C.Prefix) {
+
+		if ev.ChannelType == slack.TYPE_IM {
+			if !config.C.DM.Enabled {
+				return nil // Ignore direct messages
+			}
+			// Command prefix is optional in direct messages
+			commandText = strings.TrimPrefix(commandText, config.C.Prefix)
+			channel := &domain.Channel{
+				ID:     ev.Channel,
+				Prefix: config.C.Prefix,
+				DM:     true,
+			}
+			return s.executeCommand(commandText, channel, messageRef, executorID)
+		}
+
+		channel, ok := config.C.Slack.Channels.Find(ev.Channel)
+		if !ok {
+			return nil // Ignore messages not from the specified channels
+		}
+		prefix := channel.CommandPrefix()
+		if !strings.HasPrefix(commandText, prefix) {
 			return nil // Command prefix does not match
 		}
 
 		// Execute
-		messageRef := slack.ItemRef{
-			Channel:   ev.Channel,
-			Timestamp: ev.TimeStamp,
-		}
-		commandText = strings.Trim(commandText, config.C.Prefix)
-		return s.executeCommand(commandText, messageRef, executorID)
+		commandText = strings.TrimPrefix(commandText, prefix)
+		return s.executeCommand(commandText, toDomainChannel(channel), messageRef, executorID)
+	case *slackevents.AppHomeOpenedEvent:
+		return s.handleAppHomeOpened(ev)
 	default:
 		return nil
 	}
@@ -163,45 +208,79 @@ func (s *slackBot) handleEventsAPI(e *slackevents.EventsAPIEvent) error {
 
 func (s *slackBot) handleSlashEvent(e *slack.SlashCommand) error {
 	// Validate command execution context
-	if e.ChannelID != config.C.Slack.ChannelID {
-		return nil // Ignore messages not from the specified channel
+	channel, ok := config.C.Slack.Channels.Find(e.ChannelID)
+	if !ok {
+		return nil // Ignore messages not from the specified channels
 	}
+	commandText := strings.TrimPrefix(fmt.Sprintf("%s %s", e.Command, e.Text), slashPrefix)
 
-	// Prepare a new message to add reaction to
-	commandText := fmt.Sprintf("%s %s", e.Command, e.Text)
-	responseText := fmt.Sprintf("%s (<@%s|%s>) used slash command: %s",
-		e.UserName, e.UserID, e.UserName,
-		commandText)
-	_, ts, err := s.sock.PostMessage(e.ChannelID, slack.MsgOptionText(responseText, false))
-	if err != nil {
-		return fmt.Errorf("failed to post message in response to slash command: %w", err)
+	// Open a form instead if required arguments are missing
+	if args, err := shellquote.Split(commandText); err == nil {
+		opened, err := s.openModalIfArgsMissing(e, toDomainChannel(channel), args)
+		if opened || err != nil {
+			return err
+		}
 	}
 
 	// Execute
 	messageRef := slack.ItemRef{
-		Channel:   e.ChannelID,
-		Timestamp: ts,
+		Channel: e.ChannelID, // Slash commands have no message to add reactions to
 	}
-	commandText = strings.TrimPrefix(commandText, slashPrefix)
-	return s.executeCommand(commandText, messageRef, e.UserID)
+	ctx := s.newContext(commandText, toDomainChannel(channel), messageRef, e.UserID)
+	ctx.slash = &slashResponse{url: e.ResponseURL}
+	return s.execute(ctx)
 }
 
-func (s *slackBot) executeCommand(commandText string, messageRef slack.ItemRef, executorID string) error {
-	// Prepare command args
-	ctx := &slackContext{
+func toDomainChannel(channel *config.ChannelConfig) *domain.Channel {
+	return &domain.Channel{
+		ID:       channel.ID,
+		Prefix:   channel.CommandPrefix(),
+		Commands: channel.Commands,
+	}
+}
+
+func (s *slackBot) executeCommand(commandText string, channel *domain.Channel, messageRef slack.ItemRef, executorID string) error {
+	return s.execute(s.newContext(commandText, channel, messageRef, executorID))
+}
+
+func (s *slackBot) newContext(commandText string, channel *domain.Channel, messageRef slack.ItemRef, executorID string) *slackContext {
+	return &slackContext{
 		Context:    context.Background(),
 		api:        s.api,
 		logger:     s.logger,
 		message:    messageRef,
+		channel:    channel,
 		executorID: executorID,
+		command:    commandText,
+		started:    time.Now(),
 		args:       nil,
+		replyOptions: domain.ReplyOptions{
+			Thread:    config.C.Thread.Enabled,
+			Broadcast: config.C.Thread.Broadcast,
+		},
 	}
-	args, err := shellquote.Split(commandText)
+}
+
+func (s *slackBot) execute(ctx *slackContext) error {
+	// Prepare command args
+	args, err := shellquote.Split(ctx.command)
 	if err != nil {
-		return ctx.ReplyBad(fmt.Sprintf("failed to parse arguments: %v", err))
+		return ctx.ReplyBad(domain.Textf("failed to parse arguments: %v", err))
+	}
+	if len(args) == 0 {
+		return nil
 	}
 	ctx.args = args
 
+	// Record execution to display in App Home
+	ctx.execution = &execution{
+		command:    ctx.command,
+		executorID: ctx.executorID,
+		dm:         ctx.channel.DM,
+		at:         ctx.started,
+	}
+	s.history.add(ctx.execution)
+
 	// Execute
 	return s.rootCmd.Execute(ctx)
 }
@@ -2,12 +2,13 @@ package slack
 
 import (
 	"context"
+	"fmt"
 	"github.com/slack-go/slack"
 	"github.com/traPtitech/DevOpsBot/pkg/config"
 	"github.com/traPtitech/DevOpsBot/pkg/domain"
 	"github.com/traPtitech/DevOpsBot/pkg/utils"
 	"go.uber.org/zap"
-	"strings"
+	"time"
 )
 
 type slackContext struct {
@@ -15,13 +16,57 @@ type slackContext struct {
 	api    *slack.Client
 	logger *zap.Logger
 
-	message    slack.ItemRef
-	executorID string
-	args       []string
+	message      slack.ItemRef
+	channel      *domain.Channel
+	executorID   string
+	command      string
+	started      time.Time
+	args         []string
+	replyOptions domain.ReplyOptions
+
+	// exitCode is the exit code of the executed command, if recorded
+	exitCode *int
+	// execution is the record of this command execution, if any
+	execution *execution
+	// slash is the state of follow-up responses, if executed by a slash command
+	slash *slashResponse
+}
+
+// slashResponse holds the state of follow-up responses to a slash command.
+// Slash commands have no message to add reactions to, or to reply in the thread of,
+// so replies are posted to the response URL, each replacing the previous one.
+type slashResponse struct {
+	url string
+	// posted is true if a reply has already been posted to the response URL
+	posted bool
+}
+
+// replyStatus describes how to display a reply.
+type replyStatus struct {
+	label string
+	stamp string
+	color string
+	// final is true if the reply is the final status of the command
+	final bool
+	// ephemeral is true if the reply is only visible to the executor
+	ephemeral bool
+}
+
+var (
+	_ domain.ExitCodeRecorder    = (*slackContext)(nil)
+	_ domain.CommandPathRecorder = (*slackContext)(nil)
+)
+
+func (ctx *slackContext) Platform() string {
+	return "slack"
+}
+
+func (ctx *slackContext) Channel() *domain.Channel {
+	return ctx.channel
 }
 
 func (ctx *slackContext) Executor() string {
-	return ctx.executorID
+	return config.C.ResolveUser("slack", ctx.executorID)
 }
 
 func (ctx *slackContext) Args() []string {
@@ -34,6 +79,22 @@ func (ctx *slackContext) ShiftArgs() domain.Context {
 	return &newCtx
 }
 
+func (ctx *slackContext) WithArgs(args []string) domain.Context {
+	newCtx := *ctx
+	newCtx.args = args
+	return &newCtx
+}
+
+func (ctx *slackContext) ReplyOptions() domain.ReplyOptions {
+	return ctx.replyOptions
+}
+
+func (ctx *slackContext) WithReplyOptions(opts domain.ReplyOptions) domain.Context {
+	newCtx := *ctx
+	newCtx.replyOptions = opts
+	return &newCtx
+}
+
 func (ctx *slackContext) L() *zap.Logger {
 	return ctx.logger.With(
 		zap.String("executor", ctx.Executor()),
@@ -43,10 +104,14 @@ func (ctx *slackContext) L() *zap.Logger {
 }
 
 func (ctx *slackContext) MessageLimit() int {
-	return 2000
+	return 3 * sectionTextLimit
 }
 
 func (ctx *slackContext) StampNames() *domain.StampNames {
+	return stampNames()
+}
+
+func stampNames() *domain.StampNames {
 	return &domain.StampNames{
 		BadCommand: config.C.Stamps.BadCommand,
 		Forbid:     config.C.Stamps.Forbid,
@@ -56,30 +121,69 @@ func (ctx *slackContext) StampNames() *domain.StampNames {
 	}
 }
 
-func (ctx *slackContext) sendSlackMessage(channelID string, lines []string, color string) error {
+func (ctx *slackContext) RecordExitCode(code int) {
+	ctx.exitCode = &code
+}
+
+func (ctx *slackContext) RecordCommandPath(path []string) {
+	if ctx.execution != nil {
+		ctx.execution.setPath(path)
+	}
+}
+
+// messageOptions returns the options to post the reply content.
+func messageOptions(content *replyContent) []slack.MsgOption {
+	blocks, attachment := renderBlocks(content)
+	var options []slack.MsgOption
+	options = append(options, slack.MsgOptionText(content.title, false)) // Fallback text for notifications
+	options = append(options, slack.MsgOptionBlocks(blocks...))
+	if len(attachment.Blocks.BlockSet) > 0 {
+		options = append(options, slack.MsgOptionAttachments(attachment))
+	}
+	return options
+}
+
+func (ctx *slackContext) sendSlackMessage(channelID string, content *replyContent, extraOptions ...slack.MsgOption) error {
+	options := append(messageOptions(content), extraOptions...)
 	api := ctx.api
 	return utils.WithRetry(ctx, 10, func(ctx context.Context) error {
-		var options []slack.MsgOption
-		options = append(options, slack.MsgOptionText(lines[0], false))
-		if len(lines) >= 2 {
-			options = append(options, slack.MsgOptionAttachments(
-				slack.Attachment{
-					Color: color,
-					Fields: []slack.AttachmentField{
-						{
-							Title: "",
-							Value: strings.Join(lines[1:], "\n"),
-							Short: false,
-						},
-					},
-				},
-			))
-		}
 		_, _, err := api.PostMessage(channelID, options...)
 		return err
 	})
 }
 
+func (ctx *slackContext) sendEphemeralMessage(channelID string, content *replyContent, extraOptions ...slack.MsgOption) error {
+	options := append(messageOptions(content), extraOptions...)
+	api, userID := ctx.api, ctx.executorID
+	return utils.WithRetry(ctx, 10, func(ctx context.Context) error {
+		_, err := api.PostEphemeral(channelID, userID, options...)
+		return err
+	})
+}
+
+// sendSlashResponse posts the reply content to the response URL of the slash command.
+// Response URLs expire after a while, in which case the content is posted to the channel instead.
+func (ctx *slackContext) sendSlashResponse(content *replyContent) error {
+	options := messageOptions(content)
+	options = append(options, slack.MsgOptionResponseURL(ctx.slash.url, slack.ResponseTypeInChannel))
+	if ctx.slash.posted {
+		options = append(options, slack.MsgOptionReplaceOriginal(ctx.slash.url))
+	}
+	_, _, err := ctx.api.PostMessageContext(ctx, ctx.message.Channel, options...)
+	if err == nil {
+		ctx.slash.posted = true
+		return nil
+	}
+
+	ctx.L().Warn("failed to post to response url, posting to channel instead", zap.Error(err))
+	return ctx.sendSlackMessage(ctx.message.Channel, content)
+}
+
+// hasMessage reports whether there is a command message to add reactions to.
+func (ctx *slackContext) hasMessage() bool {
+	return ctx.message.Timestamp != ""
+}
+
 func (ctx *slackContext) pushSlackReaction(message slack.ItemRef, stampID string) error {
 	api := ctx.api
 	return utils.WithRetry(ctx, 10, func(ctx context.Context) error {
@@ -87,17 +191,56 @@ func (ctx *slackContext) pushSlackReaction(message slack.ItemRef, stampID string
 	})
 }
 
-func (ctx *slackContext) reply(color string, message ...string) error {
-	return ctx.sendSlackMessage(ctx.message.Channel, message, color)
+// reply posts the message to the channel of the command message.
+func (ctx *slackContext) reply(st replyStatus, message ...domain.Block) error {
+	title := st.label + " " + ctx.channel.Prefix + ctx.command
+	if st.stamp != "" {
+		title = ":" + st.stamp + ": " + title
+	}
+	meta := []string{fmt.Sprintf("*Executor:* <@%s>", ctx.executorID)}
+	if st.final {
+		meta = append(meta, fmt.Sprintf("*Duration:* %v", time.Since(ctx.started).Round(time.Millisecond)))
+	}
+	if ctx.exitCode != nil {
+		meta = append(meta, fmt.Sprintf("*Exit code:* %d", *ctx.exitCode))
+	}
+	content := &replyContent{
+		title:   title,
+		meta:    meta,
+		message: message,
+		color:   st.color,
+	}
+
+	var options []slack.MsgOption
+	if ctx.replyOptions.Thread && ctx.hasMessage() {
+		options = append(options, slack.MsgOptionTS(ctx.message.Timestamp))
+		if st.final && !st.ephemeral && ctx.replyOptions.Broadcast {
+			options = append(options, slack.MsgOptionBroadcast())
+		}
+	}
+	switch {
+	case st.ephemeral:
+		return ctx.sendEphemeralMessage(ctx.message.Channel, content, options...)
+	case ctx.slash != nil:
+		return ctx.sendSlashResponse(content)
+	default:
+		return ctx.sendSlackMessage(ctx.message.Channel, content, options...)
+	}
 }
 
-func (ctx *slackContext) replyWithStamp(stamp string, color string, message ...string) error {
-	err := ctx.pushSlackReaction(ctx.message, stamp)
-	if err != nil {
-		return err
+func (ctx *slackContext) replyWithStamp(st replyStatus, message ...domain.Block) error {
+	if ctx.execution != nil {
+		ctx.execution.setStatus(st.label)
+	}
+	if ctx.hasMessage() {
+		err := ctx.pushSlackReaction(ctx.message, st.stamp)
+		if err != nil {
+			return err
+		}
 	}
-	if len(message) > 0 {
-		err = ctx.reply(color, message...)
+	// Without the command message, the reply is the only way to tell the status
+	if len(message) > 0 || !ctx.hasMessage() {
+		err := ctx.reply(st, message...)
 		if err != nil {
 			return err
 		}
@@ -105,22 +248,66 @@ func (ctx *slackContext) replyWithStamp(stamp string, color string, message ...s
 	return nil
 }
 
-func (ctx *slackContext) ReplyBad(message ...string) error {
-	return ctx.replyWithStamp(config.C.Stamps.BadCommand, config.C.Slack.Colors.BadCommand, message...)
+func (ctx *slackContext) ReplyBad(message ...domain.Block) error {
+	return ctx.replyWithStamp(replyStatus{
+		label:     "Bad command",
+		stamp:     config.C.Stamps.BadCommand,
+		color:     config.C.Slack.Colors.BadCommand,
+		final:     true,
+		ephemeral: true,
+	}, message...)
+}
+
+func (ctx *slackContext) ReplyForbid(message ...domain.Block) error {
+	return ctx.replyWithStamp(replyStatus{
+		label:     "Forbidden",
+		stamp:     config.C.Stamps.Forbid,
+		color:     config.C.Slack.Colors.Forbid,
+		final:     true,
+		ephemeral: true,
+	}, message...)
 }
 
-func (ctx *slackContext) ReplyForbid(message ...string) error {
-	return ctx.replyWithStamp(config.C.Stamps.Forbid, config.C.Slack.Colors.Forbid, message...)
+func (ctx *slackContext) ReplySuccess(message ...domain.Block) error {
+	return ctx.replyWithStamp(replyStatus{
+		label: "Success",
+		stamp: config.C.Stamps.Success,
+		color: config.C.Slack.Colors.Success,
+		final: true,
+	}, message...)
 }
 
-func (ctx *slackContext) ReplySuccess(message ...string) error {
-	return ctx.replyWithStamp(config.C.Stamps.Success, config.C.Slack.Colors.Success, message...)
+func (ctx *slackContext) ReplyFailure(message ...domain.Block) error {
+	return ctx.replyWithStamp(replyStatus{
+		label: "Failure",
+		stamp: config.C.Stamps.Failure,
+		color: config.C.Slack.Colors.Failure,
+		final: true,
+	}, message...)
 }
 
-func (ctx *slackContext) ReplyFailure(message ...string) error {
-	return ctx.replyWithStamp(config.C.Stamps.Failure, config.C.Slack.Colors.Failure, message...)
+// postDMNotice posts an audit notice to the main channel, if executed in direct messages.
+// It is called on ReplyRunning, after the permission checks pass.
+func (ctx *slackContext) postDMNotice() {
+	if !ctx.channel.DM || !config.C.DM.Mirror {
+		return
+	}
+	mainChannel, ok := config.C.Slack.Channels.Main()
+	if !ok {
+		return
+	}
+	notice := fmt.Sprintf("<@%s> executed `%s%s` in direct message", ctx.executorID, config.C.Prefix, ctx.command)
+	_, _, err := ctx.api.PostMessageContext(ctx, mainChannel.ID, slack.MsgOptionText(notice, false))
+	if err != nil {
+		ctx.L().Error("failed to post direct message audit notice", zap.Error(err))
+	}
 }
 
-func (ctx *slackContext) ReplyRunning(message ...string) error {
-	return ctx.replyWithStamp(config.C.Stamps.Running, config.C.Slack.Colors.Running, message...)
+func (ctx *slackContext) ReplyRunning(message ...domain.Block) error {
+	ctx.postDMNotice()
+	return ctx.replyWithStamp(replyStatus{
+		label: "Running",
+		stamp: config.C.Stamps.Running,
+		color: config.C.Slack.Colors.Running,
+	}, message...)
 }
@@ -0,0 +1,222 @@
+package slack
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+
+	"github.com/traPtitech/DevOpsBot/pkg/config"
+	"github.com/traPtitech/DevOpsBot/pkg/domain"
+	"github.com/traPtitech/DevOpsBot/pkg/utils"
+)
+
+const (
+	// historySize is the number of recent executions to display in App Home.
+	historySize = 10
+	// historyCapacity is the number of recent executions to keep, from which those visible to the viewer are displayed.
+	historyCapacity = 100
+	// homeCommandsLimit is the maximum number of commands to display in App Home, as views can have up to 100 blocks.
+	homeCommandsLimit = 70
+
+	actionOpenCommand = "open_command"
+)
+
+// execution is a record of command execution.
+type execution struct {
+	command    string
+	executorID string
+	dm         bool
+	at         time.Time
+
+	mu sync.Mutex
+	// path is the resolved command path, which is nil if the command was not found
+	path   []string
+	status string
+}
+
+func (e *execution) setPath(path []string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.path = path
+}
+
+func (e *execution) getPath() []string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.path
+}
+
+func (e *execution) setStatus(status string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.status = status
+}
+
+func (e *execution) getStatus() string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.status
+}
+
+// history keeps recent command executions in memory.
+type history struct {
+	mu         sync.Mutex
+	executions []*execution
+}
+
+func (h *history) add(e *execution) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.executions = append(h.executions, e)
+	if len(h.executions) > historyCapacity {
+		h.executions = h.executions[len(h.executions)-historyCapacity:]
+	}
+}
+
+// recent returns recent executions, newest first.
+func (h *history) recent() []*execution {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	executions := utils.Copy(h.executions)
+	slices.Reverse(executions)
+	return executions
+}
+
+func (s *slackBot) handleAppHomeOpened(ev *slackevents.AppHomeOpenedEvent) error {
+	if ev.Tab != "home" {
+		return nil
+	}
+	return s.publishHome(ev.User)
+}
+
+// publishHome publishes App Home view for the user.
+func (s *slackBot) publishHome(userID string) error {
+	view := slack.HomeTabViewRequest{
+		Type:   slack.VTHomeTab,
+		Blocks: slack.Blocks{BlockSet: s.homeBlocks(userID)},
+	}
+	_, err := s.api.PublishView(userID, view, "")
+	if err != nil {
+		return fmt.Errorf("failed to publish home view: %w", err)
+	}
+	return nil
+}
+
+func (s *slackBot) homeBlocks(userID string) []slack.Block {
+	var blocks []slack.Block
+	blocks = append(blocks, slack.NewHeaderBlock(
+		slack.NewTextBlockObject(slack.PlainTextType, "DevOpsBot v"+utils.Version(), false, false),
+	))
+
+	// Commands
+	mainChannel, ok := config.C.Slack.Channels.Main()
+	if !ok {
+		blocks = append(blocks, markdownSection("No channels are configured for this bot."))
+		return blocks
+	}
+	channel := toDomainChannel(mainChannel)
+	infos := s.runnableCommands(userID, channel)
+	blocks = append(blocks, markdownSection(fmt.Sprintf("Commands you can run in <#%s>:", channel.ID)))
+	for i, info := range infos {
+		if i >= homeCommandsLimit {
+			blocks = append(blocks, markdownSection(fmt.Sprintf("… and %d more, type `%shelp` to see all commands", len(infos)-i, channel.Prefix)))
+			break
+		}
+		text := "`" + commandSyntax(channel, info) + "`"
+		if info.Description != "" {
+			text += "\n" + info.Description
+		}
+		button := slack.NewButtonBlockElement(
+			actionOpenCommand,
+			strings.Join(info.Path, " "),
+			slack.NewTextBlockObject(slack.PlainTextType, "Run", false, false),
+		)
+		blocks = append(blocks, slack.NewSectionBlock(
+			slack.NewTextBlockObject(slack.MarkdownType, text, false, false),
+			nil, slack.NewAccessory(button),
+		))
+	}
+
+	// Recent executions
+	blocks = append(blocks, slack.NewDividerBlock())
+	blocks = append(blocks, slack.NewHeaderBlock(
+		slack.NewTextBlockObject(slack.PlainTextType, "Recent executions", false, false),
+	))
+	executions := visibleExecutions(s.history.recent(), userID, s.rootCmd.List(config.C.ResolveUser("slack", userID)))
+	if len(executions) == 0 {
+		blocks = append(blocks, markdownSection("No commands have been executed yet."))
+	}
+	for _, e := range executions {
+		text := fmt.Sprintf("`%s%s` by <@%s> <!date^%d^{date_short_pretty} {time}|%s>",
+			channel.Prefix, e.command, e.executorID, e.at.Unix(), e.at.Format(time.RFC3339))
+		if status := e.getStatus(); status != "" {
+			text += " - " + status
+		}
+		blocks = append(blocks, slack.NewContextBlock("", slack.NewTextBlockObject(slack.MarkdownType, text, false, false)))
+	}
+
+	return blocks
+}
+
+// visibleExecutions filters the executions to display to the viewer, up to historySize.
+// Executions in direct messages are visible only to the executor,
+// and executions of commands which the viewer cannot run (or unknown commands) are hidden.
+// Commands are matched by the resolved path, as aliases and prefixes may differ from the command path.
+func visibleExecutions(executions []*execution, viewerID string, infos []*domain.CommandInfo) []*execution {
+	var visible []*execution
+	for _, e := range executions {
+		if len(visible) >= historySize {
+			break
+		}
+		if e.dm && e.executorID != viewerID {
+			continue
+		}
+		path := e.getPath()
+		canRun := path != nil && slices.ContainsFunc(infos, func(info *domain.CommandInfo) bool {
+			return info.Runnable && slices.Equal(info.Path, path)
+		})
+		if !canRun {
+			continue
+		}
+		visible = append(visible, e)
+	}
+	return visible
+}
+
+// runnableCommands returns commands which the user can execute in the channel.
+func (s *slackBot) runnableCommands(userID string, channel *domain.Channel) []*domain.CommandInfo {
+	executor := config.C.ResolveUser("slack", userID)
+	var infos []*domain.CommandInfo
+	for _, info := range s.rootCmd.List(executor) {
+		if info.Runnable && (info.Path[0] == "help" || channel.Allows(info.Path[0])) {
+			infos = append(infos, info)
+		}
+	}
+	return infos
+}
+
+func findCommand(infos []*domain.CommandInfo, path []string) (*domain.CommandInfo, bool) {
+	for _, info := range infos {
+		if slices.Equal(info.Path, path) {
+			return info, true
+		}
+	}
+	return nil, false
+}
+
+func commandSyntax(channel *domain.Channel, info *domain.CommandInfo) string {
+	syntax := channel.Prefix + strings.Join(info.Path, " ")
+	if info.ArgsSyntax != "" {
+		syntax += " " + info.ArgsSyntax
+	}
+	return syntax
+}
+
+func markdownSection(text string) *slack.SectionBlock {
+	return slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, text, false, false), nil, nil)
+}
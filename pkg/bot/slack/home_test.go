@@ -0,0 +1,37 @@
+package slack
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/traPtitech/DevOpsBot/pkg/domain"
+)
+
+func TestVisibleExecutions(t *testing.T) {
+	infos := []*domain.CommandInfo{
+		{Path: []string{"deploy"}, Runnable: false},
+		{Path: []string{"deploy", "api"}, Runnable: true},
+		{Path: []string{"help"}, Runnable: true},
+	}
+	executions := []*execution{
+		{command: "deploy api v1", path: []string{"deploy", "api"}, executorID: "U1"},
+		{command: "deploy api v2", path: []string{"deploy", "api"}, executorID: "U2", dm: true},
+		{command: "deploy api v3", path: []string{"deploy", "api"}, executorID: "U1", dm: true},
+		{command: "da v4", path: []string{"deploy", "api"}, executorID: "U2"},    // Alias
+		{command: "dep a v5", path: []string{"deploy", "api"}, executorID: "U2"}, // Prefix match
+		{command: "deploy web", path: []string{"deploy", "web"}, executorID: "U1"},
+		{command: "deploy", path: []string{"deploy"}, executorID: "U1"},
+		{command: "restart prod", executorID: "U1"},
+		{command: "hlep", executorID: "U1"},
+		{command: "help", path: []string{"help"}, executorID: "U2"},
+	}
+
+	var got []string
+	for _, e := range visibleExecutions(executions, "U1", infos) {
+		got = append(got, e.command)
+	}
+	want := []string{"deploy api v1", "deploy api v3", "da v4", "dep a v5", "help"}
+	if !slices.Equal(got, want) {
+		t.Errorf("visibleExecutions() = %q, want %q", got, want)
+	}
+}
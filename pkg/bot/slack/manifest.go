@@ -0,0 +1,78 @@
+package slack
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/traPtitech/DevOpsBot/pkg/domain"
+)
+
+const (
+	// manifestDescriptionLimit is the maximum length of slash command description.
+	manifestDescriptionLimit = 2000
+	// manifestUsageHintLimit is the maximum length of slash command usage hint.
+	manifestUsageHintLimit = 1000
+)
+
+type manifest struct {
+	Features manifestFeatures `yaml:"features"`
+}
+
+type manifestFeatures struct {
+	SlashCommands []*manifestSlashCommand `yaml:"slash_commands"`
+}
+
+type manifestSlashCommand struct {
+	Command      string `yaml:"command"`
+	Description  string `yaml:"description"`
+	UsageHint    string `yaml:"usage_hint,omitempty"`
+	ShouldEscape bool   `yaml:"should_escape"`
+}
+
+// Manifest generates a Slack app manifest snippet, which registers a slash command for each top-level command.
+// The commands are executed in Socket Mode, so no request URLs are set.
+func Manifest(infos []*domain.CommandInfo) ([]byte, error) {
+	var m manifest
+	for _, info := range infos {
+		if len(info.Path) != 1 {
+			continue
+		}
+		name := info.Path[0]
+
+		description, _, _ := strings.Cut(info.Description, "\n")
+		if description == "" {
+			description = "Execute " + name + " command" // Description is required by Slack
+		}
+
+		var subVerbs []string
+		for _, sub := range infos {
+			if len(sub.Path) == 2 && sub.Path[0] == name {
+				subVerbs = append(subVerbs, sub.Path[1])
+			}
+		}
+		usageHint := info.ArgsSyntax
+		if len(subVerbs) > 0 {
+			usageHint = "<" + strings.Join(subVerbs, "|") + "> ..."
+			if info.Runnable {
+				usageHint = "[" + strings.Join(subVerbs, "|") + "] ..."
+			}
+		}
+
+		m.Features.SlashCommands = append(m.Features.SlashCommands, &manifestSlashCommand{
+			Command:     slashPrefix + name,
+			Description: truncate(description, manifestDescriptionLimit),
+			UsageHint:   truncate(usageHint, manifestUsageHintLimit),
+		})
+	}
+
+	var b bytes.Buffer
+	enc := yaml.NewEncoder(&b)
+	enc.SetIndent(2)
+	if err := enc.Encode(&m); err != nil {
+		return nil, fmt.Errorf("failed to marshal manifest: %w", err)
+	}
+	return b.Bytes(), nil
+}
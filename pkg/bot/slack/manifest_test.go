@@ -0,0 +1,38 @@
+package slack
+
+import (
+	"testing"
+
+	"github.com/traPtitech/DevOpsBot/pkg/domain"
+)
+
+func TestManifest(t *testing.T) {
+	infos := []*domain.CommandInfo{
+		{Path: []string{"deploy"}, Description: "Deploy services\nmore details", Runnable: false},
+		{Path: []string{"deploy", "api"}, Runnable: true, ArgsSyntax: "<tag>"},
+		{Path: []string{"deploy", "web"}, Runnable: true},
+		{Path: []string{"help"}, ArgsSyntax: "[command-name [sub-commands...]]", Runnable: true},
+		{Path: []string{"ping"}, Runnable: true},
+	}
+	got, err := Manifest(infos)
+	if err != nil {
+		t.Fatalf("Manifest() error = %v", err)
+	}
+	want := `features:
+  slash_commands:
+    - command: /deploy
+      description: Deploy services
+      usage_hint: <api|web> ...
+      should_escape: false
+    - command: /help
+      description: Execute help command
+      usage_hint: '[command-name [sub-commands...]]'
+      should_escape: false
+    - command: /ping
+      description: Execute ping command
+      should_escape: false
+`
+	if string(got) != want {
+		t.Errorf("Manifest() =\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,285 @@
+package slack
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/kballard/go-shellquote"
+	"github.com/slack-go/slack"
+	"go.uber.org/zap"
+
+	"github.com/traPtitech/DevOpsBot/pkg/config"
+	"github.com/traPtitech/DevOpsBot/pkg/domain"
+)
+
+const (
+	callbackRunCommand = "run_command"
+	// blockArgs is the block ID of the free-form arguments input, for commands without declared arguments.
+	blockArgs = "args"
+	// blockArgPrefix is the block ID prefix of each declared argument input.
+	blockArgPrefix = "arg-"
+	// actionValue is the action ID of all inputs.
+	actionValue = "value"
+)
+
+// modalMetadata is stored in the private metadata of the command modal.
+type modalMetadata struct {
+	ChannelID string   `json:"channelID"`
+	Path      []string `json:"path"`
+}
+
+func (s *slackBot) handleInteractive(cb *slack.InteractionCallback) error {
+	switch cb.Type {
+	case slack.InteractionTypeBlockActions:
+		for _, action := range cb.ActionCallback.BlockActions {
+			if action.ActionID == actionOpenCommand {
+				mainChannel, ok := config.C.Slack.Channels.Main()
+				if !ok {
+					return nil
+				}
+				return s.openCommandModal(cb.TriggerID, cb.User.ID, toDomainChannel(mainChannel), strings.Fields(action.Value), nil)
+			}
+		}
+	case slack.InteractionTypeViewSubmission:
+		if cb.View.CallbackID == callbackRunCommand {
+			return s.submitCommandModal(cb)
+		}
+	}
+	return nil
+}
+
+// openCommandModal opens a modal to fill in the arguments of the command, which is executed in the channel once submitted.
+func (s *slackBot) openCommandModal(triggerID string, userID string, channel *domain.Channel, path []string, initialArgs []string) error {
+	info, ok := findCommand(s.runnableCommands(userID, channel), path)
+	if !ok {
+		return nil // Not allowed, or the command does not exist anymore
+	}
+
+	modal, err := commandModal(channel, info, initialArgs)
+	if err != nil {
+		return err
+	}
+	_, err = s.api.OpenView(triggerID, modal)
+	if err != nil {
+		return fmt.Errorf("failed to open command modal: %w", err)
+	}
+	return nil
+}
+
+// openModalIfArgsMissing opens the command modal if the slash command is invoked without its required arguments.
+func (s *slackBot) openModalIfArgsMissing(e *slack.SlashCommand, channel *domain.Channel, args []string) (opened bool, err error) {
+	info, ok := matchCommand(s.runnableCommands(e.UserID, channel), args)
+	if !ok {
+		return false, nil
+	}
+	rest := args[len(info.Path):]
+	if len(rest) >= domain.RequiredArgs(info.Args) {
+		return false, nil
+	}
+	return true, s.openCommandModal(e.TriggerID, e.UserID, channel, info.Path, rest)
+}
+
+// matchCommand returns the command with the longest path matching the leading arguments.
+func matchCommand(infos []*domain.CommandInfo, args []string) (*domain.CommandInfo, bool) {
+	var matched *domain.CommandInfo
+	for _, info := range infos {
+		if len(info.Path) > len(args) || (matched != nil && len(info.Path) <= len(matched.Path)) {
+			continue
+		}
+		if strings.Join(info.Path, " ") == strings.Join(args[:len(info.Path)], " ") {
+			matched = info
+		}
+	}
+	return matched, matched != nil
+}
+
+func commandModal(channel *domain.Channel, info *domain.CommandInfo, initialArgs []string) (slack.ModalViewRequest, error) {
+	metadata, err := json.Marshal(&modalMetadata{ChannelID: channel.ID, Path: info.Path})
+	if err != nil {
+		return slack.ModalViewRequest{}, fmt.Errorf("failed to marshal modal metadata: %w", err)
+	}
+
+	text := "`" + commandSyntax(channel, info) + "`"
+	if info.Description != "" {
+		text += "\n" + info.Description
+	}
+	var blocks []slack.Block
+	blocks = append(blocks, markdownSection(text))
+
+	switch {
+	case len(info.Args) > 0:
+		for i, arg := range info.Args {
+			initial := ""
+			if i < len(initialArgs) {
+				initial = initialArgs[i]
+			}
+			blocks = append(blocks, argInputBlock(blockArgPrefix+strconv.Itoa(i), arg, initial))
+		}
+	case info.AllowArgs:
+		input := slack.NewPlainTextInputBlockElement(nil, actionValue)
+		input.InitialValue = shellquote.Join(initialArgs...)
+		block := slack.NewInputBlock(
+			blockArgs,
+			slack.NewTextBlockObject(slack.PlainTextType, "Arguments", false, false),
+			nil, input,
+		)
+		block.Optional = true
+		if info.ArgsSyntax != "" {
+			block.Hint = slack.NewTextBlockObject(slack.PlainTextType, info.ArgsSyntax, false, false)
+		}
+		blocks = append(blocks, block)
+	}
+
+	return slack.ModalViewRequest{
+		Type:            slack.VTModal,
+		Title:           slack.NewTextBlockObject(slack.PlainTextType, "Run command", false, false),
+		Submit:          slack.NewTextBlockObject(slack.PlainTextType, "Run", false, false),
+		Close:           slack.NewTextBlockObject(slack.PlainTextType, "Cancel", false, false),
+		Blocks:          slack.Blocks{BlockSet: blocks},
+		PrivateMetadata: string(metadata),
+		CallbackID:      callbackRunCommand,
+	}, nil
+}
+
+// argInputBlock creates a select for enum arguments, and a text input otherwise.
+func argInputBlock(blockID string, arg *domain.Arg, initial string) *slack.InputBlock {
+	label := slack.NewTextBlockObject(slack.PlainTextType, arg.Name, false, false)
+	var hint *slack.TextBlockObject
+	if arg.Description != "" {
+		hint = slack.NewTextBlockObject(slack.PlainTextType, arg.Description, false, false)
+	}
+
+	var element slack.BlockElement
+	if len(arg.Enum) > 0 {
+		options := make([]*slack.OptionBlockObject, 0, len(arg.Enum))
+		for _, v := range arg.Enum {
+			options = append(options, slack.NewOptionBlockObject(v, slack.NewTextBlockObject(slack.PlainTextType, v, false, false), nil))
+		}
+		sel := slack.NewOptionsSelectBlockElement(slack.OptTypeStatic, nil, actionValue, options...)
+		for _, o := range options {
+			if o.Value == initial {
+				sel.InitialOption = o
+			}
+		}
+		element = sel
+	} else {
+		input := slack.NewPlainTextInputBlockElement(nil, actionValue)
+		input.InitialValue = initial
+		element = input
+	}
+
+	block := slack.NewInputBlock(blockID, label, hint, element)
+	block.Optional = !arg.Required
+	return block
+}
+
+// submittedArgs returns the arguments filled in the command modal,
+// and validation errors keyed by block ID, if any.
+func submittedArgs(info *domain.CommandInfo, state *slack.ViewState) ([]string, map[string]string) {
+	if state == nil {
+		return nil, nil
+	}
+
+	if len(info.Args) == 0 {
+		args, err := shellquote.Split(state.Values[blockArgs][actionValue].Value)
+		if err != nil {
+			return nil, map[string]string{blockArgs: fmt.Sprintf("failed to parse arguments: %v", err)}
+		}
+		return args, nil
+	}
+
+	var args []string
+	errs := make(map[string]string)
+	skipped := ""
+	for i, arg := range info.Args {
+		blockID := blockArgPrefix + strconv.Itoa(i)
+		action := state.Values[blockID][actionValue]
+		value := action.Value
+		if len(arg.Enum) > 0 {
+			value = action.SelectedOption.Value
+		}
+
+		if value == "" {
+			skipped = arg.Name
+			continue
+		}
+		if skipped != "" {
+			// Arguments are positional
+			errs[blockID] = fmt.Sprintf("%s needs to be filled in to set %s", skipped, arg.Name)
+			continue
+		}
+		if err := arg.Validate(value); err != nil {
+			errs[blockID] = err.Error()
+			continue
+		}
+		args = append(args, value)
+	}
+	return args, errs
+}
+
+// validateInteractive validates the modal submission, returning errors to display in the modal, if any.
+func (s *slackBot) validateInteractive(cb *slack.InteractionCallback) map[string]string {
+	if cb.Type != slack.InteractionTypeViewSubmission || cb.View.CallbackID != callbackRunCommand {
+		return nil
+	}
+	info, _, ok := s.modalCommand(cb)
+	if !ok {
+		return nil
+	}
+	_, errs := submittedArgs(info, cb.View.State)
+	return errs
+}
+
+// modalCommand returns the command and channel of the submitted modal, re-checking the permission.
+func (s *slackBot) modalCommand(cb *slack.InteractionCallback) (*domain.CommandInfo, *domain.Channel, bool) {
+	var metadata modalMetadata
+	if err := json.Unmarshal([]byte(cb.View.PrivateMetadata), &metadata); err != nil {
+		s.logger.Error("failed to unmarshal modal metadata", zap.Error(err))
+		return nil, nil, false
+	}
+	channelConfig, ok := config.C.Slack.Channels.Find(metadata.ChannelID)
+	if !ok {
+		return nil, nil, false
+	}
+	channel := toDomainChannel(channelConfig)
+	info, ok := findCommand(s.runnableCommands(cb.User.ID, channel), metadata.Path)
+	if !ok {
+		return nil, nil, false
+	}
+	return info, channel, true
+}
+
+// submitCommandModal executes the command submitted from the modal.
+func (s *slackBot) submitCommandModal(cb *slack.InteractionCallback) error {
+	info, channel, ok := s.modalCommand(cb)
+	if !ok {
+		return nil
+	}
+	args, errs := submittedArgs(info, cb.View.State)
+	if len(errs) > 0 {
+		return nil // Already displayed in the modal
+	}
+	commandText := shellquote.Join(append(info.Path, args...)...)
+
+	// Prepare a new message to add reaction to
+	responseText := fmt.Sprintf("<@%s> ran command from form: `%s%s`", cb.User.ID, channel.Prefix, commandText)
+	_, ts, err := s.api.PostMessage(channel.ID, slack.MsgOptionText(responseText, false))
+	if err != nil {
+		return fmt.Errorf("failed to post message in response to modal submission: %w", err)
+	}
+
+	// Execute
+	messageRef := slack.ItemRef{
+		Channel:   channel.ID,
+		Timestamp: ts,
+	}
+	err = s.executeCommand(commandText, channel, messageRef, cb.User.ID)
+
+	// Refresh recent executions
+	if homeErr := s.publishHome(cb.User.ID); homeErr != nil {
+		s.logger.Error("failed to refresh home view", zap.Error(homeErr))
+	}
+	return err
+}
@@ -0,0 +1,57 @@
+package slack
+
+import (
+	"regexp"
+	"slices"
+	"testing"
+
+	"github.com/slack-go/slack"
+
+	"github.com/traPtitech/DevOpsBot/pkg/domain"
+)
+
+func TestSubmittedArgs(t *testing.T) {
+	info := &domain.CommandInfo{
+		Path: []string{"deploy"},
+		Args: []*domain.Arg{
+			{Name: "env", Required: true, Enum: []string{"stg", "prod"}},
+			{Name: "tag", Pattern: regexp.MustCompile("^(?:v[0-9.]+)$")},
+			{Name: "note"},
+		},
+	}
+	state := func(env, tag, note string) *slack.ViewState {
+		return &slack.ViewState{Values: map[string]map[string]slack.BlockAction{
+			"arg-0": {actionValue: {SelectedOption: slack.OptionBlockObject{Value: env}}},
+			"arg-1": {actionValue: {Value: tag}},
+			"arg-2": {actionValue: {Value: note}},
+		}}
+	}
+
+	tests := []struct {
+		name     string
+		state    *slack.ViewState
+		wantArgs []string
+		wantErrs []string
+	}{
+		{name: "required only", state: state("stg", "", ""), wantArgs: []string{"stg"}},
+		{name: "all", state: state("prod", "v1.0", "hello"), wantArgs: []string{"prod", "v1.0", "hello"}},
+		{name: "invalid pattern", state: state("prod", "latest", ""), wantErrs: []string{"arg-1"}},
+		{name: "gap in positional args", state: state("prod", "", "hello"), wantErrs: []string{"arg-2"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args, errs := submittedArgs(info, tt.state)
+			var errKeys []string
+			for k := range errs {
+				errKeys = append(errKeys, k)
+			}
+			slices.Sort(errKeys)
+			if !slices.Equal(errKeys, tt.wantErrs) {
+				t.Fatalf("submittedArgs() errors = %v, want errors on %v", errs, tt.wantErrs)
+			}
+			if len(tt.wantErrs) == 0 && !slices.Equal(args, tt.wantArgs) {
+				t.Errorf("submittedArgs() = %q, want %q", args, tt.wantArgs)
+			}
+		})
+	}
+}
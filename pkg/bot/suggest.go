@@ -0,0 +1,129 @@
+package bot
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/samber/lo"
+
+	"github.com/traPtitech/DevOpsBot/pkg/config"
+	"github.com/traPtitech/DevOpsBot/pkg/domain"
+)
+
+// maxSuggestions is the maximum number of suggested commands displayed for an unknown command.
+const maxSuggestions = 3
+
+// suggest returns the candidates similar to the input, most similar first.
+// Candidates are similar if they start with the input, or are within a small edit distance.
+// Nothing is suggested for an empty input, which every candidate starts with.
+func suggest(input string, candidates []string) []string {
+	if input == "" {
+		return nil
+	}
+	type scored struct {
+		name     string
+		distance int
+	}
+	maxDistance := max(1, len([]rune(input))/3)
+	var matches []scored
+	for _, c := range candidates {
+		if strings.HasPrefix(c, input) {
+			matches = append(matches, scored{name: c, distance: 0})
+		} else if d := editDistance(input, c); d <= maxDistance {
+			matches = append(matches, scored{name: c, distance: d})
+		}
+	}
+	slices.SortFunc(matches, func(a, b scored) int {
+		if a.distance != b.distance {
+			return a.distance - b.distance
+		}
+		return strings.Compare(a.name, b.name)
+	})
+	names := lo.Map(matches, func(s scored, _ int) string { return s.name })
+	if len(names) > maxSuggestions {
+		names = names[:maxSuggestions]
+	}
+	return names
+}
+
+// resolvePrefix returns the only candidate starting with the input, if prefix matching is enabled.
+func resolvePrefix(input string, candidates []string) (string, bool) {
+	if !config.C.PrefixMatch || input == "" {
+		return "", false
+	}
+	matches := lo.Filter(candidates, func(c string, _ int) bool { return strings.HasPrefix(c, input) })
+	if len(matches) != 1 {
+		return "", false
+	}
+	return matches[0], true
+}
+
+// editDistance returns the Levenshtein distance between the two strings.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
+
+// isVisible reports whether the executor is allowed to execute the command or any of its sub-commands,
+// so that suggestions do not reveal commands hidden from the executor.
+func isVisible(cmd domain.Command, executor string) bool {
+	return len(cmd.List(executor)) > 0
+}
+
+// suggestionMessage returns the paragraph suggesting the non-empty list of commands.
+func suggestionMessage(suggestions []string) domain.Paragraph {
+	inlines := []domain.Inline{domain.Text("Did you mean ")}
+	for i, s := range suggestions {
+		if i > 0 {
+			inlines = append(inlines, domain.Text(", "))
+		}
+		inlines = append(inlines, domain.Code(s))
+	}
+	return append(inlines, domain.Text("?"))
+}
+
+// candidates returns the top-level command and alias names which the executor can use in the channel.
+func (dc *RootCommand) candidates(ctx domain.Context) []string {
+	var names []string
+	for name, cmd := range dc.cmds {
+		if dc.isAvailable(ctx, name) && isVisible(cmd, ctx.Executor()) {
+			names = append(names, name)
+		}
+	}
+	for name, a := range dc.aliases {
+		cmd := dc.cmds[a.target()]
+		if dc.isAvailable(ctx, a.target()) && isVisible(cmd, ctx.Executor()) {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	return names
+}
+
+// candidates returns the sub-command names which the executor can use.
+func (c *CommandInstance) candidates(executor string) []string {
+	var names []string
+	for name, cmd := range c.subCommands {
+		if isVisible(cmd, executor) {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	return names
+}
@@ -0,0 +1,161 @@
+package bot
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/traPtitech/DevOpsBot/pkg/config"
+	"github.com/traPtitech/DevOpsBot/pkg/domain/domaintest"
+)
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"deploy", "deploy", 0},
+		{"", "abc", 3},
+		{"deploi", "deploy", 1},
+		{"depoly", "deploy", 2},
+		{"status", "stats", 1},
+		{"ビルド", "ビルダ", 1},
+	}
+	for _, tt := range tests {
+		if got := editDistance(tt.a, tt.b); got != tt.want {
+			t.Errorf("editDistance(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestSuggest(t *testing.T) {
+	candidates := []string{"deploy", "deploy-all", "help", "logs", "restart", "status"}
+	tests := []struct {
+		input string
+		want  []string
+	}{
+		{"dep", []string{"deploy", "deploy-all"}},
+		{"deploi", []string{"deploy"}},
+		{"stats", []string{"status"}},
+		{"log", []string{"logs"}},
+		{"x", nil},
+		{"", nil},
+		{"unknown", nil},
+	}
+	for _, tt := range tests {
+		if got := suggest(tt.input, candidates); !slices.Equal(got, tt.want) {
+			t.Errorf("suggest(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestExecute_Suggestions(t *testing.T) {
+	commands := []*config.CommandConfig{
+		{Name: "deploy", TemplateRef: "echo", AllowArgs: true},
+		{Name: "deploy-all", TemplateRef: "echo"},
+		{Name: "destroy", TemplateRef: "echo", Operators: []string{"alice"}},
+		{
+			Name: "logs",
+			SubCommands: []*config.CommandConfig{
+				{Name: "web", TemplateRef: "echo", AllowArgs: true},
+				{Name: "worker", TemplateRef: "echo", Operators: []string{"alice"}},
+			},
+		},
+	}
+
+	tests := []struct {
+		name        string
+		prefixMatch bool
+		executor    string
+		args        []string
+		wantKinds   []domaintest.ReplyKind
+		wantLast    string
+	}{
+		{
+			name:      "typo",
+			executor:  "alice",
+			args:      []string{"deploi"},
+			wantKinds: []domaintest.ReplyKind{domaintest.ReplyBad},
+			wantLast:  "Unrecognized command `deploi`, try `/help`\n\nDid you mean `/deploy`?",
+		},
+		{
+			name:      "prefix",
+			executor:  "alice",
+			args:      []string{"de"},
+			wantKinds: []domaintest.ReplyKind{domaintest.ReplyBad},
+			wantLast:  "Did you mean `/deploy`, `/deploy-all`, `/destroy`?",
+		},
+		{
+			name:      "hidden commands are not suggested",
+			executor:  "bob",
+			args:      []string{"destroi"},
+			wantKinds: []domaintest.ReplyKind{domaintest.ReplyBad},
+			wantLast:  "Unrecognized command `destroi`, try `/help`",
+		},
+		{
+			name:      "sub-command",
+			executor:  "alice",
+			args:      []string{"logs", "workr"},
+			wantKinds: []domaintest.ReplyKind{domaintest.ReplyBad},
+			wantLast:  "Did you mean `/logs worker`?",
+		},
+		{
+			name:      "prefix match disabled",
+			executor:  "alice",
+			args:      []string{"deploy-", "stg"},
+			wantKinds: []domaintest.ReplyKind{domaintest.ReplyBad},
+			wantLast:  "Did you mean `/deploy-all`, `/deploy`?",
+		},
+		{
+			name:        "prefix match",
+			prefixMatch: true,
+			executor:    "alice",
+			args:        []string{"dep", "stg"},
+			wantKinds:   []domaintest.ReplyKind{domaintest.ReplyBad},
+			wantLast:    "Did you mean `/deploy`, `/deploy-all`?",
+		},
+		{
+			name:        "unambiguous prefix match",
+			prefixMatch: true,
+			executor:    "alice",
+			args:        []string{"deploy-"},
+			wantKinds:   []domaintest.ReplyKind{domaintest.ReplyRunning, domaintest.ReplySuccess},
+			wantLast:    ":success:",
+		},
+		{
+			name:        "exact match is preferred",
+			prefixMatch: true,
+			executor:    "alice",
+			args:        []string{"deploy", "stg"},
+			wantKinds:   []domaintest.ReplyKind{domaintest.ReplyRunning, domaintest.ReplySuccess},
+			wantLast:    "stg",
+		},
+		{
+			name:        "prefix match of sub-command",
+			prefixMatch: true,
+			executor:    "alice",
+			args:        []string{"lo", "we", "-f"},
+			wantKinds:   []domaintest.ReplyKind{domaintest.ReplyRunning, domaintest.ReplySuccess},
+			wantLast:    "-f",
+		},
+		{
+			name:        "prefix match skips hidden commands",
+			prefixMatch: true,
+			executor:    "bob",
+			args:        []string{"logs", "w", "-f"},
+			wantKinds:   []domaintest.ReplyKind{domaintest.ReplyRunning, domaintest.ReplySuccess},
+			wantLast:    "-f",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := mustCompile(t, []*config.CommandTemplateConfig{echoTemplate}, commands)
+			config.C.PrefixMatch = tt.prefixMatch
+			ctx := domaintest.NewContext(tt.executor, tt.args...)
+			if err := root.Execute(ctx); err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+			assertReplies(t, ctx, tt.wantKinds, tt.wantLast)
+		})
+	}
+}
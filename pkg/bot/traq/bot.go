@@ -9,13 +9,30 @@ import (
 	"github.com/traPtitech/DevOpsBot/pkg/domain"
 	"github.com/traPtitech/go-traq"
 	traqwsbot "github.com/traPtitech/traq-ws-bot"
+	"github.com/traPtitech/traq-ws-bot/event"
 	"github.com/traPtitech/traq-ws-bot/payload"
 	"go.uber.org/zap"
 	"strings"
+	"time"
 )
 
+var _ domain.HealthReporter = (*traqBot)(nil)
+
 type traqBot struct {
-	bot *traqwsbot.Bot
+	// bot is used for the API client only - events are received by gateway
+	bot        *traqwsbot.Bot
+	gateway    *gateway
+	logger     *zap.Logger
+	stampNames *domain.StampNames
+	rootCmd    domain.Command
+	// reruns is set if re-execution by stamps is enabled
+	reruns *reruns
+	// executed is the IDs of recent messages whose commands have been executed, if executing edited messages is enabled
+	executed *recentIDs
+	// userID is the user ID of this bot, to detect mentions
+	userID string
+	// startedAt is the time the bot was created, to ignore edits of messages sent before
+	startedAt time.Time
 }
 
 func NewBot(rootCmd domain.Command, logger *zap.Logger) (domain.Bot, error) {
@@ -32,11 +49,41 @@ func NewBot(rootCmd domain.Command, logger *zap.Logger) (domain.Bot, error) {
 	if err != nil {
 		return nil, fmt.Errorf("resolving stamp names: %w", err)
 	}
-	bot.OnMessageCreated(botMessageReceived(bot, logger, stampNames, rootCmd))
 
-	return &traqBot{
-		bot: bot,
-	}, nil
+	me, _, err := bot.API().MeApi.GetMe(context.Background()).Execute()
+	if err != nil {
+		return nil, fmt.Errorf("getting bot user: %w", err)
+	}
+
+	b := &traqBot{
+		bot:        bot,
+		gateway:    newGateway(config.C.Traq.Origin, config.C.Traq.Token, logger),
+		logger:     logger,
+		stampNames: stampNames,
+		rootCmd:    rootCmd,
+		userID:     me.Id,
+		startedAt:  time.Now(),
+	}
+	on(b.gateway, event.MessageCreated, b.botMessageReceived)
+	if config.C.DM.Enabled {
+		on(b.gateway, event.DirectMessageCreated, b.botDirectMessageReceived)
+	}
+	if config.C.Traq.AcceptEdits {
+		b.executed = newRecentIDs(executedHistorySize)
+		on(b.gateway, event.MessageUpdated, b.botMessageUpdated)
+		if config.C.DM.Enabled {
+			on(b.gateway, event.DirectMessageUpdated, b.botDirectMessageUpdated)
+		}
+	}
+	if config.C.Traq.RerunStamp != "" {
+		b.reruns = newReruns()
+		on(b.gateway, event.BotMessageStampsUpdated, b.botMessageStampsUpdated)
+	}
+	if config.C.Traq.ReconnectNotice {
+		b.gateway.onReconnect = b.postReconnectNotice
+	}
+
+	return b, nil
 }
 
 func resolveStampNames(ctx context.Context, bot *traqwsbot.Bot) (*domain.StampNames, error) {
@@ -58,56 +105,185 @@ func resolveStampNames(ctx context.Context, bot *traqwsbot.Bot) (*domain.StampNa
 	}, nil
 }
 
-func (b *traqBot) Start(_ context.Context) error {
-	err := b.bot.Start()
+func (b *traqBot) Start(ctx context.Context) error {
+	return b.gateway.run(ctx)
+}
+
+func (b *traqBot) Healthy() error {
+	return b.gateway.healthy()
+}
+
+// postReconnectNotice notifies that commands sent while disconnected were not executed.
+func (b *traqBot) postReconnectNotice(downtime time.Duration) {
+	mainChannel, ok := config.C.Traq.Channels.Main()
+	if !ok {
+		return
+	}
+	notice := fmt.Sprintf("Reconnected to traQ after %v. Commands sent while disconnected were not executed, please send them again.",
+		downtime.Round(time.Second))
+	_, err := postMessage(context.Background(), b.bot.API(), mainChannel.ID, notice)
 	if err != nil {
-		return fmt.Errorf("starting bot: %w", err)
+		b.logger.Error("failed to post reconnect notice", zap.Error(err))
 	}
-	return nil
 }
 
 // botMessageReceived BOTのMESSAGE_CREATEDイベントハンドラ
-func botMessageReceived(
-	bot *traqwsbot.Bot,
-	logger *zap.Logger,
-	stampNames *domain.StampNames,
-	rootCmd domain.Command,
-) func(p *payload.MessageCreated) {
-	return func(p *payload.MessageCreated) {
-		// Validate command execution context
-		if p.Message.User.Bot {
-			return // Ignore bots
-		}
-		if p.Message.ChannelID != config.C.Traq.ChannelID {
-			return // 指定チャンネル以外からのメッセージは無視
-		}
-		if !strings.HasPrefix(p.Message.PlainText, config.C.Prefix) {
-			return // Command prefix does not match
+func (b *traqBot) botMessageReceived(p *payload.MessageCreated) {
+	b.handleMessage(&p.Message, p.EventTime)
+}
+
+// editWindow is how long after sending a message its edits are executed.
+// Edits are for fixing typos, and must not re-execute old commands which the bot no longer remembers.
+const editWindow = 10 * time.Minute
+
+// acceptsEdit reports whether the command in the edited message can be executed.
+func (b *traqBot) acceptsEdit(message *payload.Message, eventTime time.Time) bool {
+	if message.CreatedAt.Before(b.startedAt) || eventTime.Sub(message.CreatedAt) > editWindow {
+		return false // Executed history is lost on restart, or may have been evicted
+	}
+	return !b.executed.has(message.ID) // Do not execute the same command twice
+}
+
+// botMessageUpdated BOTのMESSAGE_UPDATEDイベントハンドラ
+func (b *traqBot) botMessageUpdated(p *payload.MessageUpdated) {
+	if !b.acceptsEdit(&p.Message, p.EventTime) {
+		return
+	}
+	b.handleMessage(&p.Message, p.EventTime)
+}
+
+// botDirectMessageReceived BOTのDIRECT_MESSAGE_CREATEDイベントハンドラ
+func (b *traqBot) botDirectMessageReceived(p *payload.DirectMessageCreated) {
+	b.handleDirectMessage(&p.Message, p.EventTime)
+}
+
+// botDirectMessageUpdated BOTのDIRECT_MESSAGE_UPDATEDイベントハンドラ
+func (b *traqBot) botDirectMessageUpdated(p *payload.DirectMessageUpdated) {
+	if !b.acceptsEdit(&p.Message, p.EventTime) {
+		return
+	}
+	b.handleDirectMessage(&p.Message, p.EventTime)
+}
+
+func (b *traqBot) handleMessage(message *payload.Message, eventTime time.Time) {
+	// Validate command execution context
+	if message.User.Bot {
+		return // Ignore bots
+	}
+
+	// Commands by mentions
+	if commandText, ok := b.mentionedCommand(message); ok {
+		channel, ok := mentionChannel(message.ChannelID)
+		if !ok {
+			return // Not in the allowlist
 		}
+		b.executeCommand(message, eventTime, channel, strings.TrimPrefix(commandText, channel.Prefix))
+		return
+	}
+
+	// Commands by prefix
+	channel, ok := config.C.Traq.Channels.Find(message.ChannelID)
+	if !ok {
+		return // 指定チャンネル以外からのメッセージは無視
+	}
+	prefix := channel.CommandPrefix()
+	if !strings.HasPrefix(message.PlainText, prefix) {
+		return // Command prefix does not match
+	}
+	b.executeCommand(message, eventTime, toDomainChannel(channel), strings.TrimPrefix(message.PlainText, prefix))
+}
 
-		// Prepare command args
-		ctx := &traqContext{
-			Context: context.Background(),
+func (b *traqBot) handleDirectMessage(message *payload.Message, eventTime time.Time) {
+	// Validate command execution context
+	if message.User.Bot {
+		return // Ignore bots
+	}
 
-			api:        bot.API(),
-			logger:     logger,
-			stampNames: stampNames,
+	// Command prefix is optional in direct messages
+	b.executeCommand(message, eventTime, &domain.Channel{
+		ID:     message.ChannelID,
+		Prefix: config.C.Prefix,
+		DM:     true,
+	}, strings.TrimPrefix(message.PlainText, config.C.Prefix))
+}
 
-			p:    p,
-			args: nil,
+// mentionedCommand returns the command text following the mention to this bot at the start of the message, if any.
+func (b *traqBot) mentionedCommand(message *payload.Message) (string, bool) {
+	if !config.C.Traq.Mentions.Enabled {
+		return "", false
+	}
+	for _, e := range message.Embedded {
+		if e.Type != "user" || e.ID != b.userID {
+			continue
 		}
-		prefixStripped := strings.TrimPrefix(p.Message.PlainText, config.C.Prefix)
-		args, err := shellquote.Split(prefixStripped)
-		if err != nil {
-			_ = ctx.ReplyBad(fmt.Sprintf("failed to parse arguments: %v", err))
-			return
+		if commandText, ok := strings.CutPrefix(message.PlainText, e.Raw); ok {
+			return strings.TrimSpace(commandText), true
 		}
-		ctx.args = args
+	}
+	return "", false
+}
 
-		// Execute
-		err = rootCmd.Execute(ctx)
-		if err != nil {
-			ctx.L().Error("failed to execute command", zap.Error(err))
-		}
+// mentionChannel returns the channel to execute commands by mentions in, if allowed.
+func mentionChannel(channelID string) (*domain.Channel, bool) {
+	if channel, ok := config.C.Traq.Channels.Find(channelID); ok {
+		return toDomainChannel(channel), true
+	}
+	if len(config.C.Traq.Mentions.Channels) == 0 {
+		return &domain.Channel{ID: channelID, Prefix: config.C.Prefix}, true
+	}
+	if channel, ok := config.C.Traq.Mentions.Channels.Find(channelID); ok {
+		return toDomainChannel(channel), true
+	}
+	return nil, false
+}
+
+func toDomainChannel(channel *config.ChannelConfig) *domain.Channel {
+	return &domain.Channel{
+		ID:       channel.ID,
+		Prefix:   channel.CommandPrefix(),
+		Commands: channel.Commands,
+	}
+}
+
+func (b *traqBot) executeCommand(message *payload.Message, eventTime time.Time, channel *domain.Channel, commandText string) {
+	// Prepare command args
+	ctx := &traqContext{
+		Context: context.Background(),
+
+		api:        b.bot.API(),
+		logger:     b.logger,
+		stampNames: b.stampNames,
+
+		message:   message,
+		eventTime: eventTime,
+		channel:   channel,
+		args:      nil,
+		replyOptions: domain.ReplyOptions{
+			Thread:    config.C.Thread.Enabled,
+			Broadcast: config.C.Thread.Broadcast,
+		},
+		command:  commandText,
+		reruns:   b.reruns,
+		executed: b.executed,
+	}
+	args, err := shellquote.Split(commandText)
+	if err != nil {
+		_ = ctx.ReplyBad(domain.Textf("failed to parse arguments: %v", err))
+		return
+	}
+	if len(args) == 0 {
+		return
+	}
+	ctx.args = args
+
+	// Mark before executing, so that concurrent edits of the message do not execute the command twice
+	if !b.executed.add(message.ID) {
+		return
+	}
+
+	// Execute
+	err = b.rootCmd.Execute(ctx)
+	if err != nil {
+		ctx.L().Error("failed to execute command", zap.Error(err))
 	}
 }
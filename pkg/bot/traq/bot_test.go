@@ -0,0 +1,93 @@
+package traq
+
+import (
+	"testing"
+	"time"
+
+	"github.com/traPtitech/traq-ws-bot/payload"
+
+	"github.com/traPtitech/DevOpsBot/pkg/config"
+)
+
+func TestMentionedCommand(t *testing.T) {
+	prev := config.C
+	t.Cleanup(func() { config.C = prev })
+	config.C.Traq.Mentions.Enabled = true
+
+	b := &traqBot{userID: "bot-id"}
+	mention := payload.EmbeddedInfo{Raw: "@BOT_devops", Type: "user", ID: "bot-id"}
+	other := payload.EmbeddedInfo{Raw: "@toki", Type: "user", ID: "toki-id"}
+
+	tests := []struct {
+		name     string
+		text     string
+		embedded []payload.EmbeddedInfo
+		want     string
+		wantOK   bool
+	}{
+		{name: "mention", text: "@BOT_devops deploy stg", embedded: []payload.EmbeddedInfo{mention}, want: "deploy stg", wantOK: true},
+		{name: "mention with prefix", text: "@BOT_devops /deploy", embedded: []payload.EmbeddedInfo{mention}, want: "/deploy", wantOK: true},
+		{name: "mention not at start", text: "hi @BOT_devops", embedded: []payload.EmbeddedInfo{mention}},
+		{name: "other user", text: "@toki deploy", embedded: []payload.EmbeddedInfo{other}},
+		{name: "no mention", text: "/deploy"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := b.mentionedCommand(&payload.Message{PlainText: tt.text, Embedded: tt.embedded})
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("mentionedCommand() = %q, %v, want %q, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestMentionChannel(t *testing.T) {
+	prev := config.C
+	t.Cleanup(func() { config.C = prev })
+	config.C.Prefix = "/"
+	config.C.Traq.Channels = config.Channels{{ID: "ops", Prefix: "!", Commands: []string{"deploy"}}}
+
+	if channel, ok := mentionChannel("random"); !ok || channel.Prefix != "/" || len(channel.Commands) != 0 {
+		t.Errorf("mentionChannel() without allowlist = %+v, %v, want any channel", channel, ok)
+	}
+
+	config.C.Traq.Mentions.Channels = config.Channels{{ID: "dev"}}
+	if channel, ok := mentionChannel("ops"); !ok || channel.Prefix != "!" || len(channel.Commands) != 1 {
+		t.Errorf("mentionChannel(ops) = %+v, %v, want the channel config", channel, ok)
+	}
+	if _, ok := mentionChannel("dev"); !ok {
+		t.Errorf("mentionChannel(dev) = false, want true")
+	}
+	if _, ok := mentionChannel("random"); ok {
+		t.Errorf("mentionChannel(random) = true, want false with allowlist")
+	}
+}
+
+func TestAcceptsEdit(t *testing.T) {
+	startedAt := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	b := &traqBot{executed: newRecentIDs(1), startedAt: startedAt}
+	b.executed.add("evicted")
+	b.executed.add("latest") // Evicts "evicted" from history
+
+	now := startedAt.Add(5 * time.Minute)
+	tests := []struct {
+		name      string
+		createdAt time.Time
+		eventTime time.Time
+		id        string
+		want      bool
+	}{
+		{"recent", startedAt.Add(time.Minute), now, "new", true},
+		{"executed", startedAt.Add(time.Minute), now, "latest", false},
+		{"before start", startedAt.Add(-time.Minute), now, "new", false},
+		{"evicted and old", startedAt.Add(time.Minute), startedAt.Add(time.Minute + editWindow + time.Second), "evicted", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			message := &payload.Message{ID: tt.id, CreatedAt: tt.createdAt}
+			if got := b.acceptsEdit(message, tt.eventTime); got != tt.want {
+				t.Errorf("acceptsEdit() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
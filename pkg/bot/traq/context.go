@@ -2,11 +2,13 @@ package traq
 
 import (
 	"context"
+	"fmt"
 	"github.com/traPtitech/DevOpsBot/pkg/config"
 	"github.com/traPtitech/DevOpsBot/pkg/domain"
 	"github.com/traPtitech/traq-ws-bot/payload"
 	"go.uber.org/zap"
 	"strings"
+	"time"
 
 	"github.com/traPtitech/go-traq"
 
@@ -20,13 +22,31 @@ type traqContext struct {
 	logger     *zap.Logger
 	stampNames *domain.StampNames
 
-	// p BOTが受信したMESSAGE_CREATEDイベントの生のペイロード
-	p    *payload.MessageCreated
-	args []string
+	// message BOTが受信したMESSAGE_CREATED (またはDIRECT_MESSAGE_CREATED) イベントのメッセージ
+	message      *payload.Message
+	eventTime    time.Time
+	channel      *domain.Channel
+	args         []string
+	replyOptions domain.ReplyOptions
+
+	// command is the command text without prefix
+	command string
+	// reruns remembers reply messages to re-execute the command from, if enabled
+	reruns *reruns
+	// executed remembers the command messages which have been dispatched, if enabled
+	executed *recentIDs
+}
+
+func (ctx *traqContext) Platform() string {
+	return "traq"
+}
+
+func (ctx *traqContext) Channel() *domain.Channel {
+	return ctx.channel
 }
 
 func (ctx *traqContext) Executor() string {
-	return ctx.p.Message.User.Name
+	return config.C.ResolveUser("traq", ctx.message.User.Name)
 }
 
 func (ctx *traqContext) Args() []string {
@@ -39,11 +59,27 @@ func (ctx *traqContext) ShiftArgs() domain.Context {
 	return &newCtx
 }
 
+func (ctx *traqContext) WithArgs(args []string) domain.Context {
+	newCtx := *ctx
+	newCtx.args = args
+	return &newCtx
+}
+
+func (ctx *traqContext) ReplyOptions() domain.ReplyOptions {
+	return ctx.replyOptions
+}
+
+func (ctx *traqContext) WithReplyOptions(opts domain.ReplyOptions) domain.Context {
+	newCtx := *ctx
+	newCtx.replyOptions = opts
+	return &newCtx
+}
+
 func (ctx *traqContext) L() *zap.Logger {
 	return ctx.logger.With(
 		zap.String("executor", ctx.Executor()),
-		zap.String("command", ctx.p.Message.PlainText),
-		zap.Time("datetime", ctx.p.EventTime),
+		zap.String("command", ctx.message.PlainText),
+		zap.Time("datetime", ctx.eventTime),
 	)
 }
 
@@ -55,17 +91,26 @@ func (ctx *traqContext) StampNames() *domain.StampNames {
 	return ctx.stampNames
 }
 
-// sendTRAQMessage traQにメッセージ送信
-func (ctx *traqContext) sendTRAQMessage(channelID string, text string) error {
-	api := ctx.api
-	return utils.WithRetry(ctx, 10, func(ctx context.Context) error {
-		_, _, err := api.
+// postMessage traQにメッセージ送信
+func postMessage(ctx context.Context, api *traq.APIClient, channelID string, text string) (messageID string, err error) {
+	err = utils.WithRetry(ctx, 10, func(ctx context.Context) error {
+		message, _, err := api.
 			ChannelApi.
 			PostMessage(ctx, channelID).
 			PostMessageRequest(traq.PostMessageRequest{Content: text}).
 			Execute()
-		return err
+		if err != nil {
+			return err
+		}
+		messageID = message.Id
+		return nil
 	})
+	return messageID, err
+}
+
+// sendTRAQMessage traQにメッセージ送信
+func (ctx *traqContext) sendTRAQMessage(channelID string, text string) (messageID string, err error) {
+	return postMessage(ctx, ctx.api, channelID, text)
 }
 
 // pushTRAQStamp traQのメッセージにスタンプを押す
@@ -81,40 +126,99 @@ func (ctx *traqContext) pushTRAQStamp(messageID, stampID string) error {
 	})
 }
 
-func (ctx *traqContext) reply(message ...string) error {
-	return ctx.sendTRAQMessage(ctx.p.Message.ChannelID, strings.Join(message, "\n"))
+// messageURL returns the URL of the traQ message, which is expanded to a quote when posted.
+func messageURL(messageID string) string {
+	origin := strings.Replace(config.C.Traq.Origin, "ws", "http", 1) // ws:// -> http://, wss:// -> https://
+	return origin + "/messages/" + messageID
+}
+
+// markdownStyle displays mentions as user icons, which do not notify the users.
+func (ctx *traqContext) markdownStyle() *domain.MarkdownStyle {
+	return &domain.MarkdownStyle{
+		Stamps: ctx.stampNames,
+		Mention: func(name string) string {
+			if u, ok := config.C.Users.Find(name); ok && u.Traq != "" {
+				name = u.Traq
+			}
+			return ":@" + name + ":"
+		},
+	}
+}
+
+func (ctx *traqContext) reply(message ...domain.Block) (messageID string, err error) {
+	text := domain.Markdown(message, ctx.markdownStyle())
+	if ctx.replyOptions.Thread {
+		// traQ has no threads - quote the command message instead
+		text = text + "\n\n" + messageURL(ctx.message.ID)
+	}
+	messageID, err = ctx.sendTRAQMessage(ctx.message.ChannelID, text)
+	if err != nil {
+		return "", err
+	}
+	if ctx.reruns != nil {
+		ctx.reruns.add(messageID, &rerunEntry{channel: ctx.channel, command: ctx.command})
+	}
+	return messageID, nil
 }
 
-func (ctx *traqContext) replyWithStamp(stamp string, message ...string) error {
-	err := ctx.pushTRAQStamp(ctx.p.Message.ID, stamp)
+// replyWithStamp adds the stamp to the command message, and replies with the message if any.
+// If final is true and re-execution is enabled, the rerun stamp is added to the reply,
+// so that users can re-execute the command with one click.
+func (ctx *traqContext) replyWithStamp(stamp string, final bool, message ...domain.Block) error {
+	err := ctx.pushTRAQStamp(ctx.message.ID, stamp)
 	if err != nil {
 		return err
 	}
 	if len(message) > 0 {
-		err = ctx.reply(message...)
+		messageID, err := ctx.reply(message...)
 		if err != nil {
 			return err
 		}
+		if final && ctx.reruns != nil {
+			err = ctx.pushTRAQStamp(messageID, config.C.Traq.RerunStamp)
+			if err != nil {
+				return err
+			}
+		}
 	}
 	return nil
 }
 
-func (ctx *traqContext) ReplyBad(message ...string) error {
-	return ctx.replyWithStamp(config.C.Stamps.BadCommand, message...)
+func (ctx *traqContext) ReplyBad(message ...domain.Block) error {
+	ctx.executed.remove(ctx.message.ID) // Allow fixing the command by editing the message
+	return ctx.replyWithStamp(config.C.Stamps.BadCommand, false, message...)
+}
+
+func (ctx *traqContext) ReplyForbid(message ...domain.Block) error {
+	return ctx.replyWithStamp(config.C.Stamps.Forbid, false, message...)
 }
 
-func (ctx *traqContext) ReplyForbid(message ...string) error {
-	return ctx.replyWithStamp(config.C.Stamps.Forbid, message...)
+func (ctx *traqContext) ReplySuccess(message ...domain.Block) error {
+	return ctx.replyWithStamp(config.C.Stamps.Success, true, message...)
 }
 
-func (ctx *traqContext) ReplySuccess(message ...string) error {
-	return ctx.replyWithStamp(config.C.Stamps.Success, message...)
+func (ctx *traqContext) ReplyFailure(message ...domain.Block) error {
+	return ctx.replyWithStamp(config.C.Stamps.Failure, true, message...)
 }
 
-func (ctx *traqContext) ReplyFailure(message ...string) error {
-	return ctx.replyWithStamp(config.C.Stamps.Failure, message...)
+// postDMNotice posts an audit notice to the main channel, if executed in direct messages.
+// It is called on ReplyRunning, after the permission checks pass.
+func (ctx *traqContext) postDMNotice() {
+	if !ctx.channel.DM || !config.C.DM.Mirror {
+		return
+	}
+	mainChannel, ok := config.C.Traq.Channels.Main()
+	if !ok {
+		return
+	}
+	notice := fmt.Sprintf(":@%s: executed `%s%s` in direct message", ctx.message.User.Name, config.C.Prefix, ctx.command)
+	_, err := ctx.sendTRAQMessage(mainChannel.ID, notice)
+	if err != nil {
+		ctx.L().Error("failed to post direct message audit notice", zap.Error(err))
+	}
 }
 
-func (ctx *traqContext) ReplyRunning(message ...string) error {
-	return ctx.replyWithStamp(config.C.Stamps.Running, message...)
+func (ctx *traqContext) ReplyRunning(message ...domain.Block) error {
+	ctx.postDMNotice()
+	return ctx.replyWithStamp(config.C.Stamps.Running, false, message...)
 }
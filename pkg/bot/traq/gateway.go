@@ -0,0 +1,199 @@
+package traq
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+)
+
+const (
+	gatewayPath = "/api/v3/bots/ws"
+
+	// readTimeout is the duration to consider the connection dead without any messages or pings.
+	// traQ sends pings every 54 seconds.
+	readTimeout = 90 * time.Second
+	// writeTimeout is the timeout to write control messages.
+	writeTimeout = 5 * time.Second
+
+	initialBackoff = 1 * time.Second
+	maxBackoff     = 60 * time.Second
+)
+
+// gateway maintains the WebSocket connection to traQ bot gateway.
+//
+// traq-ws-bot reconnects on its own, but does not detect half-open connections,
+// cannot be stopped, and does not tell when it is connected.
+type gateway struct {
+	origin string
+	token  string
+	logger *zap.Logger
+
+	handlers map[string][]func(raw json.RawMessage)
+	// onReconnect is called when connected again after a disconnection.
+	onReconnect func(downtime time.Duration)
+
+	mu           sync.Mutex
+	connected    bool
+	disconnected time.Time
+	lastErr      error
+}
+
+func newGateway(origin, token string, logger *zap.Logger) *gateway {
+	return &gateway{
+		origin:   origin,
+		token:    token,
+		logger:   logger,
+		handlers: make(map[string][]func(raw json.RawMessage)),
+	}
+}
+
+// on registers an event handler. Must be called before run.
+func on[P any](g *gateway, event string, h func(p *P)) {
+	g.handlers[event] = append(g.handlers[event], func(raw json.RawMessage) {
+		var p P
+		if err := json.Unmarshal(raw, &p); err != nil {
+			g.logger.Error("unexpected event payload", zap.String("event", event), zap.Error(err))
+			return
+		}
+		h(&p)
+	})
+}
+
+// run connects to the gateway, and reconnects with backoff until ctx is cancelled.
+func (g *gateway) run(ctx context.Context) error {
+	backoff := initialBackoff
+	for {
+		connected, err := g.connect(ctx)
+		if ctx.Err() != nil {
+			return nil // Shutting down
+		}
+		g.setDisconnected(err)
+
+		if connected {
+			backoff = initialBackoff
+			g.logger.Warn("disconnected from traQ, reconnecting", zap.Error(err), zap.Duration("backoff", backoff))
+		} else {
+			g.logger.Warn("failed to connect to traQ, retrying", zap.Error(err), zap.Duration("backoff", backoff))
+		}
+
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return nil
+		}
+		if !connected {
+			backoff = min(backoff*2, maxBackoff)
+		}
+	}
+}
+
+// connect connects to the gateway and processes events until disconnected.
+// connected reports whether the connection has been established.
+func (g *gateway) connect(ctx context.Context) (connected bool, err error) {
+	header := http.Header{"Authorization": []string{"Bearer " + g.token}}
+	conn, _, err := websocket.DefaultDialer.DialContext(ctx, g.origin+gatewayPath, header)
+	if err != nil {
+		return false, fmt.Errorf("dialing gateway: %w", err)
+	}
+	defer conn.Close()
+
+	// Close the connection on shutdown, to stop reading
+	stop := context.AfterFunc(ctx, func() {
+		_ = conn.WriteControl(websocket.CloseMessage,
+			websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""),
+			time.Now().Add(writeTimeout))
+		_ = conn.Close()
+	})
+	defer stop()
+
+	// Detect half-open connections
+	extendDeadline := func() error {
+		return conn.SetReadDeadline(time.Now().Add(readTimeout))
+	}
+	if err := extendDeadline(); err != nil {
+		return true, err
+	}
+	conn.SetPingHandler(func(data string) error {
+		if err := extendDeadline(); err != nil {
+			return err
+		}
+		err := conn.WriteControl(websocket.PongMessage, []byte(data), time.Now().Add(writeTimeout))
+		if errors.Is(err, websocket.ErrCloseSent) {
+			return nil
+		}
+		return err
+	})
+
+	g.setConnected()
+	for {
+		t, p, err := conn.ReadMessage()
+		if err != nil {
+			return true, fmt.Errorf("reading message: %w", err)
+		}
+		if err := extendDeadline(); err != nil {
+			return true, err
+		}
+		if t == websocket.TextMessage {
+			g.dispatch(p)
+		}
+	}
+}
+
+func (g *gateway) dispatch(p []byte) {
+	var m struct {
+		Type string          `json:"type"`
+		Body json.RawMessage `json:"body"`
+	}
+	if err := json.Unmarshal(p, &m); err != nil {
+		g.logger.Error("unexpected message format", zap.Error(err))
+		return
+	}
+	for _, h := range g.handlers[m.Type] {
+		go h(m.Body)
+	}
+}
+
+func (g *gateway) setConnected() {
+	g.mu.Lock()
+	reconnected := !g.disconnected.IsZero()
+	downtime := time.Since(g.disconnected)
+	g.connected = true
+	g.disconnected = time.Time{}
+	g.lastErr = nil
+	g.mu.Unlock()
+
+	g.logger.Info("connected to traQ")
+	if reconnected && g.onReconnect != nil {
+		go g.onReconnect(downtime)
+	}
+}
+
+func (g *gateway) setDisconnected(err error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.connected {
+		g.disconnected = time.Now()
+	}
+	g.connected = false
+	g.lastErr = err
+}
+
+// healthy returns non-nil error if not connected to the gateway.
+func (g *gateway) healthy() error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.connected {
+		return nil
+	}
+	if g.lastErr != nil {
+		return fmt.Errorf("not connected to traQ: %w", g.lastErr)
+	}
+	return fmt.Errorf("not connected to traQ")
+}
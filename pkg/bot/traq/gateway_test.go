@@ -0,0 +1,90 @@
+package traq
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/traPtitech/traq-ws-bot/event"
+	"github.com/traPtitech/traq-ws-bot/payload"
+	"go.uber.org/zap"
+)
+
+func TestGateway_Reconnect(t *testing.T) {
+	var connections atomic.Int32
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != gatewayPath || r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		n := connections.Add(1)
+
+		msg := `{"type":"MESSAGE_CREATED","body":{"message":{"plainText":"hello"}}}`
+		_ = conn.WriteMessage(websocket.TextMessage, []byte(msg))
+		if n == 1 {
+			return // Disconnect once
+		}
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}))
+	defer server.Close()
+
+	g := newGateway("ws"+strings.TrimPrefix(server.URL, "http"), "token", zap.NewNop())
+	received := make(chan string, 2)
+	on(g, event.MessageCreated, func(p *payload.MessageCreated) {
+		received <- p.Message.PlainText
+	})
+	reconnected := make(chan struct{})
+	g.onReconnect = func(time.Duration) { close(reconnected) }
+
+	if err := g.healthy(); err == nil {
+		t.Errorf("healthy() = nil before connecting, want error")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- g.run(ctx) }()
+
+	for i := 0; i < 2; i++ {
+		select {
+		case text := <-received:
+			if text != "hello" {
+				t.Errorf("received %q, want %q", text, "hello")
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for event %d", i+1)
+		}
+	}
+	select {
+	case <-reconnected:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timed out waiting for reconnection")
+	}
+	if err := g.healthy(); err != nil {
+		t.Errorf("healthy() = %v after reconnecting, want nil", err)
+	}
+
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("run() = %v, want nil on cancellation", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("run() did not return on cancellation")
+	}
+}
@@ -0,0 +1,70 @@
+package traq
+
+import (
+	"slices"
+	"sync"
+)
+
+// executedHistorySize is the number of executed command messages to remember, to ignore their edits.
+const executedHistorySize = 1000
+
+// recentIDs remembers a bounded number of recent IDs.
+// A nil *recentIDs remembers nothing.
+type recentIDs struct {
+	size int
+
+	mu    sync.Mutex
+	ids   map[string]struct{}
+	order []string
+}
+
+func newRecentIDs(size int) *recentIDs {
+	return &recentIDs{
+		size: size,
+		ids:  make(map[string]struct{}, size),
+	}
+}
+
+// add remembers the ID, and reports whether it was not remembered yet.
+// A nil *recentIDs always reports true.
+func (r *recentIDs) add(id string) bool {
+	if r == nil {
+		return true
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.ids[id]; ok {
+		return false
+	}
+	r.ids[id] = struct{}{}
+	r.order = append(r.order, id)
+	if len(r.order) > r.size {
+		delete(r.ids, r.order[0])
+		r.order = r.order[1:]
+	}
+	return true
+}
+
+// remove forgets the ID.
+func (r *recentIDs) remove(id string) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.ids[id]; !ok {
+		return
+	}
+	delete(r.ids, id)
+	r.order = slices.DeleteFunc(r.order, func(s string) bool { return s == id })
+}
+
+func (r *recentIDs) has(id string) bool {
+	if r == nil {
+		return false
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, ok := r.ids[id]
+	return ok
+}
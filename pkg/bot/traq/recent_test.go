@@ -0,0 +1,35 @@
+package traq
+
+import "testing"
+
+func TestRecentIDs(t *testing.T) {
+	r := newRecentIDs(2)
+	if !r.add("a") {
+		t.Errorf("add(a) = false, want true")
+	}
+	if r.add("a") {
+		t.Errorf("add(a) twice = true, want false")
+	}
+
+	r.remove("a")
+	if r.has("a") {
+		t.Errorf("has(a) after remove = true, want false")
+	}
+	if !r.add("a") {
+		t.Errorf("add(a) after remove = false, want true")
+	}
+
+	r.add("b")
+	r.add("c")
+	if r.has("a") {
+		t.Errorf("has(a) = true, want the oldest ID to be forgotten")
+	}
+	if !r.has("b") || !r.has("c") {
+		t.Errorf("has(b), has(c) = false, want true")
+	}
+
+	var nilIDs *recentIDs
+	if !nilIDs.add("a") || !nilIDs.add("a") {
+		t.Errorf("add() on nil = false, want true")
+	}
+}
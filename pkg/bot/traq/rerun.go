@@ -0,0 +1,124 @@
+package traq
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/traPtitech/traq-ws-bot/payload"
+	"go.uber.org/zap"
+
+	"github.com/traPtitech/DevOpsBot/pkg/config"
+	"github.com/traPtitech/DevOpsBot/pkg/domain"
+)
+
+// rerunHistorySize is the number of bot messages to remember the commands of.
+const rerunHistorySize = 1000
+
+// rerunEntry is the command which a bot message was posted for.
+type rerunEntry struct {
+	channel *domain.Channel
+	// command is the command text without prefix
+	command string
+}
+
+// reruns remembers the commands of recent bot messages,
+// so that the commands can be re-executed by adding the rerun stamp to the messages.
+//
+// traQ only notifies bots of stamps added to their own messages,
+// so the user command messages cannot be used.
+type reruns struct {
+	mu      sync.Mutex
+	entries map[string]*rerunEntry // by message ID
+	order   []string
+	// counts is the last seen count of the rerun stamp, by message ID and user ID
+	counts map[string]map[string]int
+}
+
+func newReruns() *reruns {
+	return &reruns{
+		entries: make(map[string]*rerunEntry),
+		counts:  make(map[string]map[string]int),
+	}
+}
+
+func (r *reruns) add(messageID string, e *rerunEntry) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.entries[messageID]; ok {
+		return
+	}
+	r.entries[messageID] = e
+	r.counts[messageID] = make(map[string]int)
+	r.order = append(r.order, messageID)
+	if len(r.order) > rerunHistorySize {
+		oldest := r.order[0]
+		r.order = r.order[1:]
+		delete(r.entries, oldest)
+		delete(r.counts, oldest)
+	}
+}
+
+// stamped records the count of the rerun stamp added by the user,
+// and returns the command to re-execute if the user has added the stamp since last seen.
+func (r *reruns) stamped(messageID string, userID string, count int) (*rerunEntry, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	e, ok := r.entries[messageID]
+	if !ok {
+		return nil, false
+	}
+	last := r.counts[messageID][userID]
+	r.counts[messageID][userID] = count
+	return e, count > last
+}
+
+// botMessageStampsUpdated BOTのBOT_MESSAGE_STAMPS_UPDATEDイベントハンドラ
+func (b *traqBot) botMessageStampsUpdated(p *payload.BotMessageStampsUpdated) {
+	for _, stamp := range p.Stamps {
+		if stamp.StampID != config.C.Traq.RerunStamp {
+			continue
+		}
+		e, ok := b.reruns.stamped(p.MessageID, stamp.UserID, stamp.Count)
+		if !ok {
+			continue
+		}
+		err := b.rerun(e, stamp.UserID, p.EventTime)
+		if err != nil {
+			b.logger.Error("failed to re-execute command", zap.Error(err), zap.String("command", e.command))
+		}
+	}
+}
+
+// rerun re-executes the command, with the stamping user as the executor.
+func (b *traqBot) rerun(e *rerunEntry, userID string, eventTime time.Time) error {
+	ctx := context.Background()
+	user, _, err := b.bot.API().UserApi.GetUser(ctx, userID).Execute()
+	if err != nil {
+		return fmt.Errorf("getting user: %w", err)
+	}
+	if user.Bot {
+		return nil // Ignore bots, including the stamps added by this bot
+	}
+
+	// Prepare a new message to add reaction to
+	notice := fmt.Sprintf(":@%s: re-ran `%s%s`", user.Name, e.channel.Prefix, e.command)
+	messageID, err := postMessage(ctx, b.bot.API(), e.channel.ID, notice)
+	if err != nil {
+		return fmt.Errorf("posting rerun notice: %w", err)
+	}
+	b.reruns.add(messageID, e)
+
+	// Execute
+	b.executeCommand(&payload.Message{
+		ID: messageID,
+		User: payload.User{
+			ID:   user.Id,
+			Name: user.Name,
+		},
+		ChannelID: e.channel.ID,
+		PlainText: e.channel.Prefix + e.command,
+	}, eventTime, e.channel, e.command)
+	return nil
+}
@@ -0,0 +1,47 @@
+package traq
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestReruns_Stamped(t *testing.T) {
+	r := newReruns()
+	e := &rerunEntry{command: "deploy stg"}
+	r.add("m1", e)
+
+	steps := []struct {
+		messageID string
+		userID    string
+		count     int
+		want      bool
+	}{
+		{messageID: "m1", userID: "alice", count: 1, want: true},
+		{messageID: "m1", userID: "alice", count: 1, want: false}, // Other stamps updated
+		{messageID: "m1", userID: "bob", count: 1, want: true},
+		{messageID: "m1", userID: "alice", count: 2, want: true},  // Stamped again
+		{messageID: "m2", userID: "alice", count: 1, want: false}, // Unknown message
+	}
+	for i, s := range steps {
+		got, ok := r.stamped(s.messageID, s.userID, s.count)
+		if ok != s.want {
+			t.Errorf("step %d: stamped() ok = %v, want %v", i, ok, s.want)
+		}
+		if ok && got != e {
+			t.Errorf("step %d: stamped() = %+v, want %+v", i, got, e)
+		}
+	}
+}
+
+func TestReruns_HistorySize(t *testing.T) {
+	r := newReruns()
+	for i := 0; i <= rerunHistorySize; i++ {
+		r.add(strconv.Itoa(i), &rerunEntry{})
+	}
+	if _, ok := r.stamped("0", "alice", 1); ok {
+		t.Errorf("stamped() on the oldest message = true, want it to be forgotten")
+	}
+	if _, ok := r.stamped(strconv.Itoa(rerunHistorySize), "alice", 1); !ok {
+		t.Errorf("stamped() on the newest message = false, want true")
+	}
+}
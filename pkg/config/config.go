@@ -1,7 +1,10 @@
 package config
 
 import (
+	"fmt"
 	"os"
+	"path/filepath"
+	"reflect"
 	"strings"
 
 	"github.com/spf13/viper"
@@ -10,18 +13,36 @@ import (
 var C Config
 
 type Config struct {
-	// Mode selects the origin of the bot.
-	// Available values: "traq", "slack"
-	Mode string `mapstructure:"mode" yaml:"mode"`
+	// Mode selects the origins of the bot.
+	// Accepts either a single value or a list to run multiple platforms simultaneously.
+	// Available values: "traq", "slack", "mattermost", "discord", "matrix", "cli"
+	Mode []string `mapstructure:"mode" yaml:"mode"`
 	// Traq is traQ-related authentication config
 	Traq TraqConfig `mapstructure:"traq" yaml:"traq"`
 	// Slack is slack-related authentication config
 	Slack SlackConfig `mapstructure:"slack" yaml:"slack"`
+	// Mattermost is Mattermost-related authentication config
+	Mattermost MattermostConfig `mapstructure:"mattermost" yaml:"mattermost"`
+	// Discord is Discord-related authentication config
+	Discord DiscordConfig `mapstructure:"discord" yaml:"discord"`
+	// Matrix is Matrix-related authentication config
+	Matrix MatrixConfig `mapstructure:"matrix" yaml:"matrix"`
+	// CLI is local command line config, used to test command trees without connecting to any chat platform
+	CLI CLIConfig `mapstructure:"cli" yaml:"cli"`
 
 	// Prefix is bot command prefix
 	Prefix string `mapstructure:"prefix" yaml:"prefix"`
+	// DM configures command execution in direct messages to the bot
+	DM DMConfig `mapstructure:"dm" yaml:"dm"`
+	// Thread configures the default of how replies are posted
+	Thread ThreadConfig `mapstructure:"thread" yaml:"thread"`
 	// Stamps define which stamps to use for bot reactions
 	Stamps Stamps `mapstructure:"stamps" yaml:"stamps"`
+	// Health configures the health check endpoint
+	Health HealthConfig `mapstructure:"health" yaml:"health"`
+	// Users define the canonical identities of team members, mapped to their user IDs on each platform.
+	// The canonical names are used in "operators" config regardless of the platform.
+	Users Users `mapstructure:"users" yaml:"users"`
 
 	// TmpDir is temporary directory in which executables from inlined config "command" are created
 	TmpDir string `mapstructure:"tmpDir" yaml:"tmpDir"`
@@ -29,31 +50,378 @@ type Config struct {
 	Templates []*CommandTemplateConfig `mapstructure:"templates" yaml:"templates"`
 	// Commands define the command tree
 	Commands []*CommandConfig `mapstructure:"commands" yaml:"commands"`
+	// Aliases define short names expanding to command lines
+	Aliases []*AliasConfig `mapstructure:"aliases" yaml:"aliases"`
+	// PrefixMatch executes the command whose name uniquely starts with the given verb,
+	// such as "/dep stg" for "/deploy stg". Applies to top-level commands, aliases and sub-commands.
+	PrefixMatch bool `mapstructure:"prefixMatch" yaml:"prefixMatch"`
+
+	// Include is a list of additional config files (glob patterns, relative to the directory of this config file)
+	// contributing templates, commands and aliases. (example: "conf.d/*.yaml")
+	Include []string `mapstructure:"include" yaml:"include"`
+	// Strict fails loading the config if the config file contains unknown (such as misspelled) keys.
+	// If not set, unknown keys are only warned.
+	Strict bool `mapstructure:"strict" yaml:"strict"`
 
 	// Servers define server auth information if this bot binary is used with "server" sub-command
 	Servers ServersConfig `mapstructure:"servers" yaml:"servers"`
+
+	// rawSecrets are the unresolved values containing secret references by config path, to redact them on printing
+	rawSecrets map[string]string
 }
 
 type TraqConfig struct {
 	// Origin is WebSocket traQ origin. (example: wss://q.trap.jp)
 	Origin string `mapstructure:"origin" yaml:"origin"`
 	// ChannelID is the channel in which to await for commands
+	//
+	// Deprecated: use Channels instead. If set, it is treated as a channel with no restrictions.
 	ChannelID string `mapstructure:"channelID" yaml:"channelID"`
+	// Channels are the channels in which to await for commands
+	Channels Channels `mapstructure:"channels" yaml:"channels"`
 	// Token is traQ bot token
 	Token string `mapstructure:"token" yaml:"token"`
+	// Identities optionally maps traQ user names to the operator names used in "operators" config.
+	//
+	// Deprecated: use "users" config instead, which maps the same identity on all platforms.
+	Identities Identities `mapstructure:"identities" yaml:"identities"`
+	// ReconnectNotice posts a notice to the main channel when the bot reconnects after a disconnection,
+	// as messages sent while disconnected are not processed.
+	ReconnectNotice bool `mapstructure:"reconnectNotice" yaml:"reconnectNotice"`
+	// RerunStamp is the stamp ID to re-execute the command of a bot reply message, with the stamping user as the executor.
+	// If left empty, re-execution by stamps is disabled.
+	RerunStamp string `mapstructure:"rerunStamp" yaml:"rerunStamp"`
+	// AcceptEdits executes commands from edited messages, unless the command has already been executed.
+	AcceptEdits bool `mapstructure:"acceptEdits" yaml:"acceptEdits"`
+	// Mentions configures command execution by mentioning the bot. (example: "@BOT_devops deploy stg")
+	Mentions MentionsConfig `mapstructure:"mentions" yaml:"mentions"`
+}
+
+type MentionsConfig struct {
+	// Enabled accepts commands prefixed with a mention to the bot, instead of the command prefix.
+	Enabled bool `mapstructure:"enabled" yaml:"enabled"`
+	// Channels is an optional allowlist of the channels to accept mentions in, in addition to "channels".
+	// If left empty, mentions are accepted in any channel which the bot is in.
+	Channels Channels `mapstructure:"channels" yaml:"channels"`
 }
 
 type SlackConfig struct {
 	OAuthToken string `mapstructure:"oauthToken" yaml:"oauthToken"`
 	AppToken   string `mapstructure:"appToken" yaml:"appToken"`
 	// ChannelID is the channel in which to await for commands
+	//
+	// Deprecated: use Channels instead. If set, it is treated as a channel with no restrictions.
 	ChannelID string `mapstructure:"channelID" yaml:"channelID"`
+	// Channels are the channels in which to await for commands
+	Channels Channels `mapstructure:"channels" yaml:"channels"`
 	// TrustedWorkflows is the list of bot IDs of trusted workflows.
 	//
 	// Trusted workflows are allowed to impersonate the execution user via adding user mention at the start of message.
 	TrustedWorkflows []string `mapstructure:"trustedWorkflows" yaml:"trustedWorkflows"`
 	// Colors sets colors used for reply blocks.
 	Colors Stamps `mapstructure:"colors" yaml:"colors"`
+	// Identities optionally maps Slack member or bot IDs to the operator names used in "operators" config.
+	//
+	// Deprecated: use "users" config instead, which maps the same identity on all platforms.
+	Identities Identities `mapstructure:"identities" yaml:"identities"`
+}
+
+type MattermostConfig struct {
+	// Origin is Mattermost server origin. (example: https://mattermost.example.com)
+	Origin string `mapstructure:"origin" yaml:"origin"`
+	// Token is the access token of Mattermost bot account
+	Token string `mapstructure:"token" yaml:"token"`
+	// Channels are the channels in which to await for commands
+	Channels Channels `mapstructure:"channels" yaml:"channels"`
+	// Identities optionally maps Mattermost user IDs to the operator names used in "operators" config.
+	//
+	// Deprecated: use "users" config instead, which maps the same identity on all platforms.
+	Identities Identities `mapstructure:"identities" yaml:"identities"`
+	// MessageLimit is the reply character limit, which depends on the server version and settings.
+	MessageLimit int `mapstructure:"messageLimit" yaml:"messageLimit"`
+	// Stamps optionally overrides "stamps" config for Mattermost, which reacts with emoji names (example: "white_check_mark").
+	Stamps Stamps `mapstructure:"stamps" yaml:"stamps"`
+}
+
+type DiscordConfig struct {
+	// Token is Discord bot token
+	Token string `mapstructure:"token" yaml:"token"`
+	// Channels are the channels in which to await for commands
+	Channels Channels `mapstructure:"channels" yaml:"channels"`
+	// SlashCommands registers application commands (slash commands) generated from the command tree.
+	SlashCommands bool `mapstructure:"slashCommands" yaml:"slashCommands"`
+	// GuildID optionally registers the slash commands to the guild only, which is reflected immediately.
+	// If left empty, the slash commands are registered globally.
+	GuildID string `mapstructure:"guildID" yaml:"guildID"`
+	// Colors sets colors used for reply embeds.
+	Colors Stamps `mapstructure:"colors" yaml:"colors"`
+	// Stamps optionally overrides "stamps" config for Discord, which reacts with Unicode emojis (example: "✅")
+	// or custom emojis (example: "devops_ok:123456789012345678") instead of stamp names.
+	Stamps Stamps `mapstructure:"stamps" yaml:"stamps"`
+	// Identities optionally maps Discord user IDs (snowflakes) to the operator names used in "operators" config.
+	//
+	// Deprecated: use "users" config instead, which maps the same identity on all platforms.
+	Identities Identities `mapstructure:"identities" yaml:"identities"`
+}
+
+type MatrixConfig struct {
+	// Homeserver is Matrix homeserver URL. (example: https://matrix.example.org)
+	Homeserver string `mapstructure:"homeserver" yaml:"homeserver"`
+	// Token is the access token of Matrix bot account
+	Token string `mapstructure:"token" yaml:"token"`
+	// Rooms are the rooms in which to await for commands. (example: "!abcdefg:example.org")
+	// The bot joins these rooms when invited.
+	Rooms Channels `mapstructure:"rooms" yaml:"rooms"`
+	// Stamps optionally overrides "stamps" config for Matrix, which reacts with Unicode emojis (example: "✅").
+	Stamps Stamps `mapstructure:"stamps" yaml:"stamps"`
+	// Identities optionally maps Matrix user IDs to the operator names used in "operators" config.
+	//
+	// Deprecated: use "users" config instead, which maps the same identity on all platforms.
+	Identities Identities `mapstructure:"identities" yaml:"identities"`
+}
+
+type Channels []*ChannelConfig
+
+// AllChannels returns the channels of all platforms, which may restrict available commands.
+func (c *Config) AllChannels() Channels {
+	var all Channels
+	all = append(all, c.Traq.Channels...)
+	all = append(all, c.Traq.Mentions.Channels...)
+	all = append(all, c.Slack.Channels...)
+	all = append(all, c.Mattermost.Channels...)
+	all = append(all, c.Discord.Channels...)
+	all = append(all, c.Matrix.Rooms...)
+	return all
+}
+
+type ChannelConfig struct {
+	// ID is the platform-specific channel ID.
+	ID string `mapstructure:"id" yaml:"id"`
+	// Prefix optionally overrides the bot command prefix in this channel.
+	Prefix string `mapstructure:"prefix" yaml:"prefix"`
+	// Commands is an optional list of top-level command names available in this channel.
+	// If left empty, all commands will be available.
+	Commands []string `mapstructure:"commands" yaml:"commands"`
+}
+
+// Find returns the channel config of the given channel ID.
+func (cs Channels) Find(id string) (*ChannelConfig, bool) {
+	for _, c := range cs {
+		if c.ID == id {
+			return c, true
+		}
+	}
+	return nil, false
+}
+
+// CommandPrefix returns the bot command prefix in this channel.
+func (c *ChannelConfig) CommandPrefix() string {
+	if c.Prefix != "" {
+		return c.Prefix
+	}
+	return C.Prefix
+}
+
+// Main returns the first channel, if any.
+func (cs Channels) Main() (*ChannelConfig, bool) {
+	if len(cs) == 0 {
+		return nil, false
+	}
+	return cs[0], true
+}
+
+// withLegacyChannel appends the deprecated single channel ID config, if set.
+func (cs Channels) withLegacyChannel(id string) Channels {
+	if id == "" {
+		return cs
+	}
+	if _, ok := cs.Find(id); ok {
+		return cs
+	}
+	return append(cs, &ChannelConfig{ID: id})
+}
+
+type Users []*UserConfig
+
+type UserConfig struct {
+	// Name is the canonical identity, used in "operators" config.
+	Name string `mapstructure:"name" yaml:"name"`
+	// Traq is the traQ user name. (example: "toki")
+	Traq string `mapstructure:"traq" yaml:"traq"`
+	// Slack is the Slack member ID. (example: "U01234ABCDE")
+	Slack string `mapstructure:"slack" yaml:"slack"`
+	// Mattermost is the Mattermost user ID. (example: "4xp9fdt77pncbef59f4k1qe83o")
+	Mattermost string `mapstructure:"mattermost" yaml:"mattermost"`
+	// Discord is the Discord user ID. (example: "123456789012345678")
+	Discord string `mapstructure:"discord" yaml:"discord"`
+	// Matrix is the Matrix user ID. (example: "@toki:example.org")
+	Matrix string `mapstructure:"matrix" yaml:"matrix"`
+}
+
+// ID returns the user ID on the platform, or empty string if not mapped.
+func (u *UserConfig) ID(platform string) string {
+	switch platform {
+	case "traq":
+		return u.Traq
+	case "slack":
+		return u.Slack
+	case "mattermost":
+		return u.Mattermost
+	case "discord":
+		return u.Discord
+	case "matrix":
+		return u.Matrix
+	default:
+		return ""
+	}
+}
+
+// Find returns the user of the given canonical name.
+func (us Users) Find(name string) (*UserConfig, bool) {
+	for _, u := range us {
+		if u.Name == name {
+			return u, true
+		}
+	}
+	return nil, false
+}
+
+// FindByID returns the user mapped to the given platform-specific user ID.
+func (us Users) FindByID(platform string, id string) (*UserConfig, bool) {
+	for _, u := range us {
+		if userID := u.ID(platform); userID != "" && userID == id {
+			return u, true
+		}
+	}
+	return nil, false
+}
+
+// Validate checks that the names and platform-specific IDs are not duplicated.
+func (us Users) Validate() error {
+	names := make(map[string]bool, len(us))
+	for _, u := range us {
+		if u.Name == "" {
+			return fmt.Errorf("user needs to have a name")
+		}
+		if names[u.Name] {
+			return fmt.Errorf("user %s is defined more than once", u.Name)
+		}
+		names[u.Name] = true
+
+		for _, platform := range []string{"traq", "slack", "mattermost", "discord", "matrix"} {
+			if other, _ := us.FindByID(platform, u.ID(platform)); other != nil && other != u {
+				return fmt.Errorf("users %s and %s have the same %s ID %s", other.Name, u.Name, platform, u.ID(platform))
+			}
+		}
+	}
+	return nil
+}
+
+// ResolveUser returns the canonical name of the platform-specific user ID.
+// "users" config takes precedence over "identities" config of the platform.
+//
+// If the user is not mapped, the ID itself is returned.
+// If the unmapped ID collides with a canonical name (such as a user-chosen username equal to another user's name),
+// the platform-qualified ID (example: "discord:toki") is returned instead, so that it never gains the rights of that name.
+func (c *Config) ResolveUser(platform string, id string) string {
+	if u, ok := c.Users.FindByID(platform, id); ok {
+		return u.Name
+	}
+	if name, ok := c.platformIdentities(platform).Lookup(id); ok {
+		return name
+	}
+	if c.isCanonicalName(id) {
+		return platform + ":" + id
+	}
+	return id
+}
+
+func (c *Config) platformIdentities(platform string) Identities {
+	switch platform {
+	case "traq":
+		return c.Traq.Identities
+	case "slack":
+		return c.Slack.Identities
+	case "mattermost":
+		return c.Mattermost.Identities
+	case "discord":
+		return c.Discord.Identities
+	case "matrix":
+		return c.Matrix.Identities
+	default:
+		return nil
+	}
+}
+
+// isCanonicalName reports whether the name is mapped from any user ID, by "users" or "identities" config.
+func (c *Config) isCanonicalName(name string) bool {
+	if _, ok := c.Users.Find(name); ok {
+		return true
+	}
+	for _, platform := range []string{"traq", "slack", "mattermost", "discord", "matrix"} {
+		for _, identity := range c.platformIdentities(platform) {
+			if identity.Name == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Identities maps platform-specific user IDs to operator names,
+// so that the same "operators" config can be shared across platforms.
+type Identities []*IdentityConfig
+
+type IdentityConfig struct {
+	// ID is the platform-specific user ID.
+	ID string `mapstructure:"id" yaml:"id"`
+	// Name is the operator name to use in "operators" config.
+	Name string `mapstructure:"name" yaml:"name"`
+}
+
+// Lookup returns the operator name of the given platform-specific user ID, if mapped.
+func (ids Identities) Lookup(id string) (string, bool) {
+	for _, identity := range ids {
+		if identity.ID == id {
+			return identity.Name, true
+		}
+	}
+	return "", false
+}
+
+type CLIConfig struct {
+	// Executor is the fake user ID treated as the executor of all commands typed in.
+	Executor string `mapstructure:"executor" yaml:"executor"`
+	// MessageLimit is the reply character limit to emulate. Defaults to that of traQ.
+	MessageLimit int `mapstructure:"messageLimit" yaml:"messageLimit"`
+}
+
+type DMConfig struct {
+	// Enabled allows executing commands in direct messages to the bot.
+	// Only commands with "allowDM" set (and their sub-commands) can be executed in direct messages.
+	Enabled bool `mapstructure:"enabled" yaml:"enabled"`
+	// Operators is an optional list of users who are allowed to execute commands in direct messages.
+	// This is checked independently of, and in addition to, the operators config of each command.
+	// If left empty, everyone will be able to execute commands in direct messages.
+	Operators []string `mapstructure:"operators" yaml:"operators"`
+	// Mirror posts a one-line audit notice to the first channel in "channels" of each platform,
+	// whenever a command starts running in direct messages, after passing the permission checks.
+	Mirror bool `mapstructure:"mirror" yaml:"mirror"`
+}
+
+type HealthConfig struct {
+	// Addr is the address to serve the health check endpoint (GET /healthz) on. (example: ":8080")
+	// If left empty, the endpoint is disabled.
+	Addr string `mapstructure:"addr" yaml:"addr"`
+}
+
+type ThreadConfig struct {
+	// Enabled posts replies in the thread of the command message in Slack,
+	// and as replies quoting the command message in traQ.
+	Enabled bool `mapstructure:"enabled" yaml:"enabled"`
+	// Broadcast additionally posts the final status reply to the channel, when Enabled is set.
+	// Only supported in Slack.
+	Broadcast bool `mapstructure:"broadcast" yaml:"broadcast"`
 }
 
 type Stamps struct {
@@ -64,6 +432,19 @@ type Stamps struct {
 	Running    string `mapstructure:"running" yaml:"running"`
 }
 
+type AliasConfig struct {
+	// Name is the alias name, which is used like a top-level command.
+	Name string `mapstructure:"name" yaml:"name"`
+	// Description is the alias description, displayed in help.
+	Description string `mapstructure:"description" yaml:"description"`
+	// Command is the command line to expand into, without prefix. (example: "k8s rollout restart deployment {app} -n {ns=prod}")
+	//
+	// The alias arguments fill the placeholders in order of appearance.
+	// "{name}" is a required placeholder, and "{name=default}" is an optional placeholder with the default value.
+	// Remaining arguments are appended to the command line.
+	Command string `mapstructure:"command" yaml:"command"`
+}
+
 type CommandTemplateConfig struct {
 	// Name is template name referenced by "templateRef" by each command
 	Name string `mapstructure:"name" yaml:"name"`
@@ -85,13 +466,28 @@ type CommandConfig struct {
 	TemplateRef string `mapstructure:"templateRef" yaml:"templateRef"`
 	// Description should describe what this command does in one line.
 	Description string `mapstructure:"description" yaml:"description"`
+	// LongDescription optionally documents this command in detail, displayed in help of this command.
+	LongDescription string `mapstructure:"longDescription" yaml:"longDescription"`
+	// Examples are optional usage examples, written as the arguments following this command. (example: "stg v1.2.0")
+	Examples []string `mapstructure:"examples" yaml:"examples"`
 	// AllowArgs is a flag to allow passing extra user command arguments to exec arguments.
 	AllowArgs bool `mapstructure:"allowArgs" yaml:"allowArgs"`
 	// ArgsSyntax is an optional arguments syntax to display in help command.
 	ArgsSyntax string `mapstructure:"argsSyntax" yaml:"argsSyntax"`
+	// Args optionally declares the positional arguments of this command.
+	// If declared, user arguments are validated against the declarations, and AllowArgs is implied.
+	// ArgsSyntax is generated from the declarations if not set.
+	Args []*ArgConfig `mapstructure:"args" yaml:"args"`
 	// ArgsPrefix is always prefixed the arguments (before the user-provided arguments, if any) when executing the command template.
 	ArgsPrefix []string `mapstructure:"argsPrefix" yaml:"argsPrefix"`
-	// Operators is an optional list of user IDs (traQ IDs in traQ, member or bot IDs in Slack)
+	// Thread optionally overrides the global "thread.enabled" config for this command (and any sub-commands).
+	Thread *bool `mapstructure:"thread" yaml:"thread"`
+	// ThreadBroadcast optionally overrides the global "thread.broadcast" config for this command (and any sub-commands).
+	ThreadBroadcast *bool `mapstructure:"threadBroadcast" yaml:"threadBroadcast"`
+	// AllowDM allows executing this command (and any sub-commands) in direct messages to the bot.
+	AllowDM bool `mapstructure:"allowDM" yaml:"allowDM"`
+	// Operators is an optional list of users (canonical names defined in "users" config,
+	// or platform-specific user IDs such as traQ IDs in traQ, member or bot IDs in Slack)
 	// who are allowed to execute this command (and any sub-commands).
 	// If left empty, everyone will be able to execute this command (and any sub-commands).
 	Operators []string `mapstructure:"operators" yaml:"operators"`
@@ -101,6 +497,23 @@ type CommandConfig struct {
 	SubCommands []*CommandConfig `mapstructure:"subCommands" yaml:"subCommands"`
 }
 
+type ArgConfig struct {
+	// Name is the argument name, displayed in help messages and forms.
+	Name string `mapstructure:"name" yaml:"name"`
+	// Description optionally describes the argument.
+	Description string `mapstructure:"description" yaml:"description"`
+	// Required makes the argument mandatory. Required arguments must come before optional ones.
+	Required bool `mapstructure:"required" yaml:"required"`
+	// Enum is an optional list of allowed values.
+	//
+	// Cannot be set together with Pattern.
+	Enum []string `mapstructure:"enum" yaml:"enum"`
+	// Pattern is an optional regular expression which the value must fully match.
+	//
+	// Cannot be set together with Enum.
+	Pattern string `mapstructure:"pattern" yaml:"pattern"`
+}
+
 type ServersConfig struct {
 	Conoha struct {
 		Origin struct {
@@ -118,12 +531,21 @@ func init() {
 
 	viper.SetDefault("traq.origin", "wss://q.trap.jp")
 	viper.SetDefault("traq.channelID", "")
+	viper.SetDefault("traq.channels", nil)
 	viper.SetDefault("traq.token", "")
+	viper.SetDefault("traq.identities", nil)
+	viper.SetDefault("traq.reconnectNotice", false)
+	viper.SetDefault("traq.rerunStamp", "")
+	viper.SetDefault("traq.acceptEdits", false)
+	viper.SetDefault("traq.mentions.enabled", false)
+	viper.SetDefault("traq.mentions.channels", nil)
 
 	viper.SetDefault("slack.oauthToken", "")
 	viper.SetDefault("slack.appToken", "")
 	viper.SetDefault("slack.channelID", "")
+	viper.SetDefault("slack.channels", nil)
 	viper.SetDefault("slack.trustedWorkflows", nil)
+	viper.SetDefault("slack.identities", nil)
 
 	viper.SetDefault("slack.colors.badCommand", "#dd0204")
 	viper.SetDefault("slack.colors.forbid", "#dd0204")
@@ -131,14 +553,74 @@ func init() {
 	viper.SetDefault("slack.colors.failure", "#dd0204")
 	viper.SetDefault("slack.colors.running", "#e3e4e6")
 
+	viper.SetDefault("mattermost.origin", "")
+	viper.SetDefault("mattermost.token", "")
+	viper.SetDefault("mattermost.channels", nil)
+	viper.SetDefault("mattermost.identities", nil)
+	viper.SetDefault("mattermost.messageLimit", 16000)
+
+	viper.SetDefault("mattermost.stamps.badCommand", "")
+	viper.SetDefault("mattermost.stamps.forbid", "")
+	viper.SetDefault("mattermost.stamps.success", "")
+	viper.SetDefault("mattermost.stamps.failure", "")
+	viper.SetDefault("mattermost.stamps.running", "")
+
+	viper.SetDefault("discord.token", "")
+	viper.SetDefault("discord.channels", nil)
+	viper.SetDefault("discord.slashCommands", false)
+	viper.SetDefault("discord.guildID", "")
+	viper.SetDefault("discord.identities", nil)
+
+	viper.SetDefault("discord.colors.badCommand", "#dd0204")
+	viper.SetDefault("discord.colors.forbid", "#dd0204")
+	viper.SetDefault("discord.colors.success", "#56c59c")
+	viper.SetDefault("discord.colors.failure", "#dd0204")
+	viper.SetDefault("discord.colors.running", "#e3e4e6")
+
+	viper.SetDefault("discord.stamps.badCommand", "")
+	viper.SetDefault("discord.stamps.forbid", "")
+	viper.SetDefault("discord.stamps.success", "")
+	viper.SetDefault("discord.stamps.failure", "")
+	viper.SetDefault("discord.stamps.running", "")
+
+	viper.SetDefault("matrix.homeserver", "")
+	viper.SetDefault("matrix.token", "")
+	viper.SetDefault("matrix.rooms", nil)
+	viper.SetDefault("matrix.identities", nil)
+
+	viper.SetDefault("matrix.stamps.badCommand", "")
+	viper.SetDefault("matrix.stamps.forbid", "")
+	viper.SetDefault("matrix.stamps.success", "")
+	viper.SetDefault("matrix.stamps.failure", "")
+	viper.SetDefault("matrix.stamps.running", "")
+
+	viper.SetDefault("users", nil)
+	viper.SetDefault("aliases", nil)
+	viper.SetDefault("prefixMatch", false)
+
+	viper.SetDefault("cli.executor", "")
+	viper.SetDefault("cli.messageLimit", 9900)
+
 	viper.SetDefault("prefix", "/")
 
+	viper.SetDefault("dm.enabled", false)
+	viper.SetDefault("dm.operators", nil)
+	viper.SetDefault("dm.mirror", false)
+
+	viper.SetDefault("thread.enabled", false)
+	viper.SetDefault("thread.broadcast", false)
+
 	viper.SetDefault("stamps.badCommand", "")
 	viper.SetDefault("stamps.forbid", "")
 	viper.SetDefault("stamps.success", "")
 	viper.SetDefault("stamps.failure", "")
 	viper.SetDefault("stamps.running", "")
 
+	viper.SetDefault("health.addr", "")
+
+	viper.SetDefault("include", nil)
+	viper.SetDefault("strict", false)
+
 	viper.SetDefault("tmpDir", "/commands")
 	viper.SetDefault("templates", nil)
 	viper.SetDefault("commands", nil)
@@ -150,7 +632,9 @@ func init() {
 	viper.SetDefault("servers.conoha.tenantID", "")
 }
 
-func init() {
+// Load reads the config file and environment variables into C.
+// C is left unchanged if the config is invalid, so that Load can be called again to reload the config.
+func Load() error {
 	configFile := os.Getenv("CONFIG_FILE")
 	if configFile == "" {
 		configFile = "./config.yaml"
@@ -161,10 +645,34 @@ func init() {
 
 	err := viper.ReadInConfig()
 	if err != nil {
-		panic(err)
+		return fmt.Errorf("reading config file: %w", err)
+	}
+	var c Config
+	err = viper.Unmarshal(&c)
+	if err != nil {
+		return fmt.Errorf("unmarshaling config: %w", err)
 	}
-	err = viper.Unmarshal(&C)
+
+	// Detect keys ignored by decoding, such as misspelled ones
+	err = reportUnknownKeys(configFile, reflect.TypeOf(Config{}), c.Strict)
 	if err != nil {
-		panic(err)
+		return err
 	}
+
+	// Merge included files
+	err = c.loadIncludes(configFile)
+	if err != nil {
+		return err
+	}
+
+	// Resolve secret references such as "${env:TRAQ_TOKEN}"
+	err = c.resolveSecrets(filepath.Dir(configFile))
+	if err != nil {
+		return fmt.Errorf("resolving secrets: %w", err)
+	}
+
+	c.Traq.Channels = c.Traq.Channels.withLegacyChannel(c.Traq.ChannelID)
+	c.Slack.Channels = c.Slack.Channels.withLegacyChannel(c.Slack.ChannelID)
+	C = c
+	return nil
 }
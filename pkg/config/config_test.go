@@ -0,0 +1,81 @@
+package config
+
+import "testing"
+
+func TestResolveUser(t *testing.T) {
+	c := &Config{
+		Users: Users{
+			{Name: "toki", Traq: "toki", Slack: "U01", Matrix: "@toki:example.org"},
+		},
+		Slack: SlackConfig{Identities: Identities{{ID: "U02", Name: "bob"}, {ID: "U01", Name: "shadowed"}}},
+	}
+	tests := []struct {
+		platform string
+		id       string
+		want     string
+	}{
+		{platform: "traq", id: "toki", want: "toki"},
+		{platform: "slack", id: "U01", want: "toki"},
+		{platform: "matrix", id: "@toki:example.org", want: "toki"},
+		{platform: "slack", id: "U02", want: "bob"},
+		{platform: "slack", id: "U03", want: "U03"},
+		{platform: "mattermost", id: "", want: ""},
+	}
+	for _, tt := range tests {
+		if got := c.ResolveUser(tt.platform, tt.id); got != tt.want {
+			t.Errorf("ResolveUser(%q, %q) = %q, want %q", tt.platform, tt.id, got, tt.want)
+		}
+	}
+}
+
+func TestResolveUser_Collision(t *testing.T) {
+	c := &Config{
+		Users: Users{
+			{Name: "toki", Traq: "toki_t", Discord: "100"},
+		},
+		Mattermost: MattermostConfig{Identities: Identities{{ID: "cp20_m", Name: "cp20"}}},
+	}
+	tests := []struct {
+		platform string
+		id       string
+		want     string
+	}{
+		{platform: "traq", id: "toki_t", want: "toki"},
+		{platform: "discord", id: "100", want: "toki"},
+		// Unmapped IDs equal to canonical names must not gain their rights
+		{platform: "traq", id: "toki", want: "traq:toki"},
+		{platform: "discord", id: "toki", want: "discord:toki"},
+		{platform: "mattermost", id: "toki", want: "mattermost:toki"},
+		{platform: "slack", id: "cp20", want: "slack:cp20"},
+		{platform: "mattermost", id: "cp20_m", want: "cp20"},
+		// Other unmapped IDs are returned as is
+		{platform: "traq", id: "bob", want: "bob"},
+	}
+	for _, tt := range tests {
+		if got := c.ResolveUser(tt.platform, tt.id); got != tt.want {
+			t.Errorf("ResolveUser(%q, %q) = %q, want %q", tt.platform, tt.id, got, tt.want)
+		}
+	}
+}
+
+func TestUsersValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		users   Users
+		wantErr bool
+	}{
+		{name: "valid", users: Users{{Name: "a", Slack: "U01"}, {Name: "b", Slack: "U02"}}},
+		{name: "unmapped platforms are not duplicates", users: Users{{Name: "a"}, {Name: "b"}}},
+		{name: "no name", users: Users{{Slack: "U01"}}, wantErr: true},
+		{name: "duplicate name", users: Users{{Name: "a"}, {Name: "a"}}, wantErr: true},
+		{name: "duplicate ID", users: Users{{Name: "a", Traq: "x"}, {Name: "b", Traq: "x"}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.users.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
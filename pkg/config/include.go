@@ -0,0 +1,134 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"slices"
+
+	"github.com/spf13/viper"
+)
+
+// IncludedConfig is the config contributed by each file listed in "include".
+type IncludedConfig struct {
+	// Templates are appended to the command templates.
+	Templates []*CommandTemplateConfig `mapstructure:"templates" yaml:"templates"`
+	// Commands are appended to the top-level commands, with their sub-commands.
+	Commands []*CommandConfig `mapstructure:"commands" yaml:"commands"`
+	// Aliases are appended to the aliases.
+	Aliases []*AliasConfig `mapstructure:"aliases" yaml:"aliases"`
+}
+
+// includeSources records the file in which each template, command, and alias is defined, to detect conflicts.
+type includeSources map[string]string
+
+func (s includeSources) add(kind, name, file string) error {
+	if name == "" {
+		return nil // Reported on compilation
+	}
+	key := kind + " " + name
+	if prev, ok := s[key]; ok {
+		return fmt.Errorf("%s %s is defined in both %s and %s", kind, name, prev, file)
+	}
+	s[key] = file
+	return nil
+}
+
+// loadIncludes merges the files matching "include" patterns in order.
+// Files matching a pattern are merged in lexical order, and each file is merged at most once.
+func (c *Config) loadIncludes(configFile string) error {
+	if len(c.Include) == 0 {
+		return nil
+	}
+
+	sources := make(includeSources)
+	if err := sources.addAll(c.Templates, c.Commands, c.Aliases, configFile); err != nil {
+		return err
+	}
+
+	files, err := includedFiles(configFile, c.Include)
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		inc, err := readIncluded(file, c.Strict)
+		if err != nil {
+			return err
+		}
+		if err := sources.addAll(inc.Templates, inc.Commands, inc.Aliases, file); err != nil {
+			return err
+		}
+		c.Templates = append(c.Templates, inc.Templates...)
+		c.Commands = append(c.Commands, inc.Commands...)
+		c.Aliases = append(c.Aliases, inc.Aliases...)
+	}
+	return nil
+}
+
+func (s includeSources) addAll(templates []*CommandTemplateConfig, commands []*CommandConfig, aliases []*AliasConfig, file string) error {
+	for _, t := range templates {
+		if err := s.add("template", t.Name, file); err != nil {
+			return err
+		}
+	}
+	for _, cmd := range commands {
+		if err := s.add("command", cmd.Name, file); err != nil {
+			return err
+		}
+	}
+	for _, a := range aliases {
+		if err := s.add("alias", a.Name, file); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// includedFiles resolves the include patterns relative to the directory of the config file.
+func includedFiles(configFile string, patterns []string) ([]string, error) {
+	base := filepath.Dir(configFile)
+	seen := map[string]bool{filepath.Clean(configFile): true}
+	var files []string
+	for _, pattern := range patterns {
+		if !filepath.IsAbs(pattern) {
+			pattern = filepath.Join(base, pattern)
+		}
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid include pattern %s: %w", pattern, err)
+		}
+		if len(matches) == 0 && !hasMeta(pattern) {
+			return nil, fmt.Errorf("included file %s not found", pattern)
+		}
+		slices.Sort(matches)
+		for _, m := range matches {
+			if !seen[m] {
+				seen[m] = true
+				files = append(files, m)
+			}
+		}
+	}
+	return files, nil
+}
+
+// hasMeta reports whether the path contains any of the glob magic characters.
+func hasMeta(path string) bool {
+	return slices.ContainsFunc([]rune(path), func(r rune) bool { return r == '*' || r == '?' || r == '[' })
+}
+
+func readIncluded(file string, strict bool) (*IncludedConfig, error) {
+	// Decode in the same manner as the main config file
+	v := viper.New()
+	v.SetConfigFile(file)
+	if err := v.ReadInConfig(); err != nil {
+		return nil, fmt.Errorf("reading included file %s: %w", file, err)
+	}
+	var inc IncludedConfig
+	if err := v.Unmarshal(&inc); err != nil {
+		return nil, fmt.Errorf("unmarshaling included file %s: %w", file, err)
+	}
+	if err := reportUnknownKeys(file, reflect.TypeOf(IncludedConfig{}), strict); err != nil {
+		return nil, err
+	}
+	return &inc, nil
+}
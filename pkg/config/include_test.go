@@ -0,0 +1,117 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/samber/lo"
+)
+
+// writeFiles writes the files relative to a temporary directory, and returns the directory.
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestLoadIncludes(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"conf.d/b.yaml": "commands:\n  - name: logs\n    templateRef: echo\n",
+		"conf.d/a.yaml": "templates:\n  - name: echo\n    command: echo\ncommands:\n  - name: deploy\n    templateRef: echo\n    subCommands:\n      - name: stg\naliases:\n  - name: dp\n    command: deploy\n",
+		"extra.yml":     "commands:\n  - name: status\n    templateRef: echo\n",
+	})
+	c := &Config{
+		Include:  []string{"conf.d/*.yaml", "extra.yml", "conf.d/a.yaml"},
+		Commands: []*CommandConfig{{Name: "ping"}},
+	}
+	if err := c.loadIncludes(filepath.Join(dir, "config.yaml")); err != nil {
+		t.Fatalf("loadIncludes() error = %v", err)
+	}
+
+	commands := lo.Map(c.Commands, func(cmd *CommandConfig, _ int) string { return cmd.Name })
+	if want := []string{"ping", "deploy", "logs", "status"}; !slices.Equal(commands, want) {
+		t.Errorf("commands = %v, want %v", commands, want)
+	}
+	if len(c.Templates) != 1 || c.Templates[0].Command != "echo" {
+		t.Errorf("templates = %+v, want echo template", c.Templates)
+	}
+	if len(c.Aliases) != 1 || c.Aliases[0].Name != "dp" {
+		t.Errorf("aliases = %+v, want dp alias", c.Aliases)
+	}
+	if len(c.Commands[1].SubCommands) != 1 || c.Commands[1].SubCommands[0].Name != "stg" {
+		t.Errorf("sub-commands = %+v, want stg", c.Commands[1].SubCommands)
+	}
+}
+
+func TestLoadIncludes_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string]string
+		include []string
+		strict  bool
+		wantErr []string
+	}{
+		{
+			name: "conflict with main config",
+			files: map[string]string{
+				"conf.d/a.yaml": "commands:\n  - name: ping\n",
+			},
+			include: []string{"conf.d/*.yaml"},
+			wantErr: []string{"command ping is defined in both", "config.yaml and ", "conf.d/a.yaml"},
+		},
+		{
+			name: "conflict between included files",
+			files: map[string]string{
+				"conf.d/a.yaml": "templates:\n  - name: echo\n    command: echo a\n",
+				"conf.d/b.yaml": "templates:\n  - name: echo\n    command: echo b\n",
+			},
+			include: []string{"conf.d/*.yaml"},
+			wantErr: []string{"template echo is defined in both", "conf.d/a.yaml and ", "conf.d/b.yaml"},
+		},
+		{
+			name:    "missing file",
+			include: []string{"missing.yaml"},
+			wantErr: []string{"included file", "missing.yaml not found"},
+		},
+		{
+			name: "unknown keys in strict mode",
+			files: map[string]string{
+				"conf.d/a.yaml": "commands:\n  - name: deploy\n    allowArg: true\nprefix: \"!\"\n",
+			},
+			include: []string{"conf.d/*.yaml"},
+			strict:  true,
+			wantErr: []string{"conf.d/a.yaml:3: commands[0].allowArg", "conf.d/a.yaml:4: prefix"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writeFiles(t, tt.files)
+			c := &Config{
+				Include:  tt.include,
+				Strict:   tt.strict,
+				Commands: []*CommandConfig{{Name: "ping"}},
+			}
+			err := c.loadIncludes(filepath.Join(dir, "config.yaml"))
+			if err == nil {
+				t.Fatalf("loadIncludes() error = nil, want %v", tt.wantErr)
+			}
+			for _, want := range tt.wantErr {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("loadIncludes() error = %v, want it to contain %q", err, want)
+				}
+			}
+		})
+	}
+}
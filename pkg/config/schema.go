@@ -0,0 +1,97 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// schemaID is the published URL of the JSON Schema of the config file.
+const schemaID = "https://raw.githubusercontent.com/traPtitech/DevOpsBot/main/config.schema.json"
+
+// Schema generates the JSON Schema of the config file from the Config structs,
+// for editor completion and validation.
+//
+// Unknown keys are disallowed, and named struct types are defined in "$defs" to allow recursion.
+func Schema() ([]byte, error) {
+	g := &schemaGenerator{defs: make(map[string]any)}
+	schema := g.typeSchema(reflect.TypeOf(Config{}))
+	root := map[string]any{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"$id":     schemaID,
+		"title":   "DevOpsBot config",
+		"$ref":    schema["$ref"],
+		"$defs":   g.defs,
+	}
+
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(root); err != nil {
+		return nil, fmt.Errorf("marshalling schema: %w", err)
+	}
+	return b.Bytes(), nil
+}
+
+type schemaGenerator struct {
+	defs map[string]any
+}
+
+func (g *schemaGenerator) typeSchema(typ reflect.Type) map[string]any {
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+
+	switch typ.Kind() {
+	case reflect.Struct:
+		if typ.Name() == "" {
+			return g.structSchema(typ)
+		}
+		if _, ok := g.defs[typ.Name()]; !ok {
+			g.defs[typ.Name()] = nil // Reserve for recursive types
+			g.defs[typ.Name()] = g.structSchema(typ)
+		}
+		return map[string]any{"$ref": "#/$defs/" + typ.Name()}
+	case reflect.Slice:
+		items := g.typeSchema(typ.Elem())
+		if typ.Elem().Kind() == reflect.String {
+			// A single string is also decoded into a list, split by commas
+			return map[string]any{"anyOf": []any{
+				map[string]any{"type": "array", "items": items},
+				map[string]any{"type": "string"},
+			}}
+		}
+		return map[string]any{"type": "array", "items": items}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": g.typeSchema(typ.Elem())}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	default:
+		return map[string]any{}
+	}
+}
+
+func (g *schemaGenerator) structSchema(typ reflect.Type) map[string]any {
+	properties := make(map[string]any, typ.NumField())
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		properties[configKey(field)] = g.typeSchema(field.Type)
+	}
+	return map[string]any{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+}
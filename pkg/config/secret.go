@@ -0,0 +1,142 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// secretRefRegexp matches secret references, "${env:NAME}" and "${file:PATH}".
+var secretRefRegexp = regexp.MustCompile(`\$\{(env|file):([^}]*)\}`)
+
+// credentialPaths are the config paths of credentials, which are redacted even if written without secret references,
+// or set by environment variables. (example: SLACK_APPTOKEN)
+var credentialPaths = []string{
+	"traq.token",
+	"slack.oauthToken",
+	"slack.appToken",
+	"mattermost.token",
+	"discord.token",
+	"matrix.token",
+	"servers.conoha.password",
+}
+
+// redactedValue replaces the credentials without secret references on printing.
+const redactedValue = "<redacted>"
+
+// resolveSecrets replaces the secret references in all string values of the config.
+// Relative file paths are resolved against base, the directory of the config file.
+//
+// The unresolved values are recorded by config path, so that Redacted can restore them.
+func (c *Config) resolveSecrets(base string) error {
+	c.rawSecrets = make(map[string]string)
+	return walkStrings(reflect.ValueOf(c).Elem(), "", func(path string, v reflect.Value) error {
+		s := v.String()
+		matches := secretRefRegexp.FindAllStringSubmatchIndex(s, -1)
+		if len(matches) == 0 {
+			return nil
+		}
+
+		var sb strings.Builder
+		last := 0
+		for _, m := range matches {
+			ref, kind, name := s[m[0]:m[1]], s[m[2]:m[3]], s[m[4]:m[5]]
+			value, err := readSecret(kind, name, base)
+			if err != nil {
+				// Do not include the value, as it may be partially read
+				return fmt.Errorf("%s: resolving %s: %w", path, ref, err)
+			}
+			sb.WriteString(s[last:m[0]])
+			sb.WriteString(value)
+			last = m[1]
+		}
+		sb.WriteString(s[last:])
+		c.rawSecrets[path] = s
+		v.SetString(sb.String())
+		return nil
+	})
+}
+
+func readSecret(kind, name, base string) (string, error) {
+	switch kind {
+	case "env":
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			return "", fmt.Errorf("environment variable %s is not set", name)
+		}
+		return value, nil
+	case "file":
+		if !filepath.IsAbs(name) {
+			name = filepath.Join(base, name)
+		}
+		b, err := os.ReadFile(name)
+		if err != nil {
+			return "", fmt.Errorf("reading secret file: %w", err)
+		}
+		return strings.TrimRight(string(b), "\r\n"), nil // Files usually end with a line break
+	default:
+		return "", fmt.Errorf("unknown secret kind %s", kind)
+	}
+}
+
+// Redacted returns a copy of the config, with the values containing resolved secrets restored to their references,
+// and the other credentials replaced by redactedValue.
+func (c *Config) Redacted() (*Config, error) {
+	b, err := yaml.Marshal(c)
+	if err != nil {
+		return nil, fmt.Errorf("copying config: %w", err)
+	}
+	var redacted Config
+	if err := yaml.Unmarshal(b, &redacted); err != nil {
+		return nil, fmt.Errorf("copying config: %w", err)
+	}
+
+	err = walkStrings(reflect.ValueOf(&redacted).Elem(), "", func(path string, v reflect.Value) error {
+		if raw, ok := c.rawSecrets[path]; ok {
+			v.SetString(raw)
+		} else if v.String() != "" && slices.Contains(credentialPaths, path) {
+			v.SetString(redactedValue)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &redacted, nil
+}
+
+// walkStrings calls fn with each settable string value in exported fields, with its config path.
+func walkStrings(v reflect.Value, path string, fn func(path string, v reflect.Value) error) error {
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return nil
+		}
+		return walkStrings(v.Elem(), path, fn)
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			if err := walkStrings(v.Field(i), joinPath(path, configKey(field)), fn); err != nil {
+				return err
+			}
+		}
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			if err := walkStrings(v.Index(i), fmt.Sprintf("%s[%d]", path, i), fn); err != nil {
+				return err
+			}
+		}
+	case reflect.String:
+		return fn(path, v)
+	}
+	return nil
+}
@@ -0,0 +1,179 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestResolveSecrets(t *testing.T) {
+	t.Setenv("DEVOPSBOT_TEST_TOKEN", "s3cret")
+	dir := writeFiles(t, map[string]string{
+		"secrets/password": "hunter2\n",
+	})
+
+	c := &Config{
+		Traq: TraqConfig{Token: "${env:DEVOPSBOT_TEST_TOKEN}"},
+		Commands: []*CommandConfig{
+			{Name: "deploy", ArgsPrefix: []string{"--token=${env:DEVOPSBOT_TEST_TOKEN}", "${HOME}"}},
+		},
+		Templates: []*CommandTemplateConfig{
+			{Name: "login", Command: "login -p ${file:secrets/password}"},
+		},
+	}
+	c.Servers.Conoha.Password = "${file:" + dir + "/secrets/password}"
+	if err := c.resolveSecrets(dir); err != nil {
+		t.Fatalf("resolveSecrets() error = %v", err)
+	}
+
+	if got := c.Traq.Token; got != "s3cret" {
+		t.Errorf("traq.token = %q, want %q", got, "s3cret")
+	}
+	if got := c.Commands[0].ArgsPrefix; got[0] != "--token=s3cret" || got[1] != "${HOME}" {
+		t.Errorf("argsPrefix = %q, want [--token=s3cret ${HOME}]", got)
+	}
+	if got := c.Templates[0].Command; got != "login -p hunter2" {
+		t.Errorf("templates[0].command = %q, want %q", got, "login -p hunter2")
+	}
+	if got := c.Servers.Conoha.Password; got != "hunter2" {
+		t.Errorf("servers.conoha.password = %q, want %q", got, "hunter2")
+	}
+
+	// Redacted config does not contain the resolved values
+	redacted, err := c.Redacted()
+	if err != nil {
+		t.Fatalf("Redacted() error = %v", err)
+	}
+	b, err := yaml.Marshal(redacted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	printed := string(b)
+	for _, secret := range []string{"s3cret", "hunter2"} {
+		if strings.Contains(printed, secret) {
+			t.Errorf("redacted config contains secret %q:\n%s", secret, printed)
+		}
+	}
+	if redacted.Traq.Token != "${env:DEVOPSBOT_TEST_TOKEN}" {
+		t.Errorf("redacted traq.token = %q, want the reference", redacted.Traq.Token)
+	}
+	if c.Traq.Token != "s3cret" {
+		t.Errorf("Redacted() modified the original config")
+	}
+}
+
+func TestRedacted_OnlySecretPaths(t *testing.T) {
+	t.Setenv("DEVOPSBOT_TEST_TOKEN", "1")
+	c := &Config{
+		Traq:     TraqConfig{Token: "${env:DEVOPSBOT_TEST_TOKEN}"},
+		Commands: []*CommandConfig{{Name: "deploy-v1", Description: "Deploy with 1 replica"}},
+	}
+	if err := c.resolveSecrets(t.TempDir()); err != nil {
+		t.Fatalf("resolveSecrets() error = %v", err)
+	}
+
+	redacted, err := c.Redacted()
+	if err != nil {
+		t.Fatalf("Redacted() error = %v", err)
+	}
+	if redacted.Traq.Token != "${env:DEVOPSBOT_TEST_TOKEN}" {
+		t.Errorf("redacted traq.token = %q, want the reference", redacted.Traq.Token)
+	}
+	if got := redacted.Commands[0]; got.Name != "deploy-v1" || got.Description != "Deploy with 1 replica" {
+		t.Errorf("redacted commands[0] = %q, %q, want them unchanged", got.Name, got.Description)
+	}
+}
+
+func TestResolveSecrets_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  *Config
+		wantErr string
+	}{
+		{
+			name:    "missing env",
+			config:  &Config{Slack: SlackConfig{AppToken: "${env:DEVOPSBOT_TEST_MISSING}"}},
+			wantErr: "slack.appToken: resolving ${env:DEVOPSBOT_TEST_MISSING}: environment variable DEVOPSBOT_TEST_MISSING is not set",
+		},
+		{
+			name:    "missing file",
+			config:  &Config{Commands: []*CommandConfig{{Name: "a"}, {Name: "b", ArgsPrefix: []string{"${file:missing}"}}}},
+			wantErr: "commands[1].argsPrefix[0]: resolving ${file:missing}: reading secret file",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.resolveSecrets(t.TempDir())
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("resolveSecrets() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoad_Reload(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"config.yaml": "traq:\n  token: ${env:DEVOPSBOT_TEST_TOKEN}\n",
+	})
+	t.Setenv("CONFIG_FILE", filepath.Join(dir, "config.yaml"))
+	t.Cleanup(func() { C = Config{} })
+
+	t.Setenv("DEVOPSBOT_TEST_TOKEN", "old")
+	if err := Load(); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("DEVOPSBOT_TEST_TOKEN", "new")
+	if err := Load(); err != nil {
+		t.Fatal(err)
+	}
+	if C.Traq.Token != "new" {
+		t.Errorf("reloaded token = %q, want %q", C.Traq.Token, "new")
+	}
+
+	// Invalid config keeps the current one
+	if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte("traq:\n  token: ${env:DEVOPSBOT_TEST_MISSING}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := Load(); err == nil {
+		t.Fatal("Load() with missing secret succeeded")
+	}
+	if C.Traq.Token != "new" {
+		t.Errorf("token after failed reload = %q, want %q", C.Traq.Token, "new")
+	}
+}
+
+func TestRedacted_Credentials(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"config.yaml": "slack:\n  oauthToken: xoxb-plaintext\ntraq:\n  token: ${env:DEVOPSBOT_TEST_TOKEN}\n",
+	})
+	t.Setenv("CONFIG_FILE", filepath.Join(dir, "config.yaml"))
+	t.Setenv("DEVOPSBOT_TEST_TOKEN", "s3cret")
+	t.Setenv("SLACK_APPTOKEN", "xapp-leak")
+	t.Cleanup(func() { C = Config{} })
+	if err := Load(); err != nil {
+		t.Fatal(err)
+	}
+	if C.Slack.AppToken != "xapp-leak" {
+		t.Fatalf("slack.appToken = %q, want it set by the environment", C.Slack.AppToken)
+	}
+
+	redacted, err := C.Redacted()
+	if err != nil {
+		t.Fatalf("Redacted() error = %v", err)
+	}
+	if got := redacted.Slack.AppToken; got != redactedValue {
+		t.Errorf("redacted slack.appToken = %q, want %q", got, redactedValue)
+	}
+	if got := redacted.Slack.OAuthToken; got != redactedValue {
+		t.Errorf("redacted slack.oauthToken = %q, want %q", got, redactedValue)
+	}
+	if got := redacted.Traq.Token; got != "${env:DEVOPSBOT_TEST_TOKEN}" {
+		t.Errorf("redacted traq.token = %q, want the reference", got)
+	}
+	if got := redacted.Discord.Token; got != "" {
+		t.Errorf("redacted discord.token = %q, want empty tokens to be kept empty", got)
+	}
+}
@@ -0,0 +1,152 @@
+package config
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// UnknownKey is a key in the config file which does not correspond to any config field.
+type UnknownKey struct {
+	// Path is the YAML path of the key. (example: "commands[0].allowArg")
+	Path string
+	// Line is the line number of the key in the config file.
+	Line int
+}
+
+func (k *UnknownKey) String() string {
+	return fmt.Sprintf("%s (line %d)", k.Path, k.Line)
+}
+
+// UnknownKeysError reports keys in the config file which are silently ignored by decoding.
+type UnknownKeysError struct {
+	File string
+	Keys []*UnknownKey
+}
+
+func (e *UnknownKeysError) Error() string {
+	lines := make([]string, 0, len(e.Keys))
+	for _, k := range e.Keys {
+		lines = append(lines, fmt.Sprintf("%s:%d: %s", e.File, k.Line, k.Path))
+	}
+	return "unknown keys in config file (misspelled?):\n  " + strings.Join(lines, "\n  ")
+}
+
+// reportUnknownKeys warns keys in the YAML or JSON config file which are ignored by decoding,
+// or returns them as an error if strict is set.
+func reportUnknownKeys(file string, typ reflect.Type, strict bool) error {
+	if ext := filepath.Ext(file); ext != ".yaml" && ext != ".yml" && ext != ".json" {
+		return nil
+	}
+	err := checkUnknownKeys(file, typ)
+	if err != nil && strict {
+		return err
+	}
+	if err != nil {
+		slog.Warn(err.Error())
+	}
+	return nil
+}
+
+// checkUnknownKeys reports keys in the YAML config file which do not correspond to any field of the config type.
+// Keys are matched case-insensitively, in the same manner as decoding.
+func checkUnknownKeys(file string, typ reflect.Type) error {
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return fmt.Errorf("reading config file: %w", err)
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(b, &doc); err != nil {
+		return fmt.Errorf("parsing config file: %w", err)
+	}
+	keys := unknownKeys(&doc, typ, "")
+	if len(keys) > 0 {
+		return &UnknownKeysError{File: file, Keys: keys}
+	}
+	return nil
+}
+
+func unknownKeys(node *yaml.Node, typ reflect.Type, path string) []*UnknownKey {
+	for node.Kind == yaml.DocumentNode || node.Kind == yaml.AliasNode {
+		if node.Kind == yaml.AliasNode {
+			node = node.Alias
+		} else if len(node.Content) > 0 {
+			node = node.Content[0]
+		} else {
+			return nil
+		}
+	}
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+
+	var keys []*UnknownKey
+	switch typ.Kind() {
+	case reflect.Struct:
+		if node.Kind != yaml.MappingNode {
+			return nil // Type mismatches are reported by decoding
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			if key.Value == "<<" {
+				// Merge keys
+				keys = append(keys, unknownKeys(value, typ, path)...)
+				continue
+			}
+			keyPath := joinPath(path, key.Value)
+			field, ok := fieldByKey(typ, key.Value)
+			if !ok {
+				keys = append(keys, &UnknownKey{Path: keyPath, Line: key.Line})
+				continue
+			}
+			keys = append(keys, unknownKeys(value, field.Type, keyPath)...)
+		}
+	case reflect.Slice:
+		if node.Kind != yaml.SequenceNode {
+			return nil
+		}
+		for i, elem := range node.Content {
+			keys = append(keys, unknownKeys(elem, typ.Elem(), fmt.Sprintf("%s[%d]", path, i))...)
+		}
+	case reflect.Map:
+		if node.Kind != yaml.MappingNode {
+			return nil
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			keys = append(keys, unknownKeys(node.Content[i+1], typ.Elem(), joinPath(path, node.Content[i].Value))...)
+		}
+	}
+	return keys
+}
+
+// fieldByKey returns the struct field decoded from the key.
+func fieldByKey(typ reflect.Type, key string) (reflect.StructField, bool) {
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if field.IsExported() && strings.EqualFold(configKey(field), key) {
+			return field, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+// configKey returns the config key of the struct field.
+func configKey(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("mapstructure"), ",")
+	if name == "" {
+		return field.Name
+	}
+	return name
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
@@ -0,0 +1,91 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestCheckUnknownKeys(t *testing.T) {
+	content := `mode: [traq, slack]
+prefix: "!"
+traq:
+  token: xxx
+  channelid: legacy
+stamps: &stamps
+  success: ok
+commands:
+  - name: deploy
+    allowArg: true
+    subcommands:
+      - name: stg
+        operatorz: [toki]
+  - name: status
+    args:
+      - name: env
+        requried: true
+base: &base
+  name: base
+templates:
+  - <<: *base
+    command: echo
+    exec: /bin/true
+`
+	file := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	err := checkUnknownKeys(file, reflect.TypeOf(Config{}))
+	var unknownErr *UnknownKeysError
+	if !errors.As(err, &unknownErr) {
+		t.Fatalf("checkUnknownKeys() error = %v, want UnknownKeysError", err)
+	}
+	want := []*UnknownKey{
+		{Path: "commands[0].allowArg", Line: 10},
+		{Path: "commands[0].subcommands[0].operatorz", Line: 13},
+		{Path: "commands[1].args[0].requried", Line: 17},
+		{Path: "base", Line: 18},
+		{Path: "templates[0].exec", Line: 23},
+	}
+	if !reflect.DeepEqual(unknownErr.Keys, want) {
+		t.Errorf("unknown keys = %v, want %v", unknownErr.Keys, want)
+	}
+}
+
+func TestCheckUnknownKeys_Valid(t *testing.T) {
+	content := `mode: traq
+commands:
+  - name: deploy
+    allowArgs: true
+    subCommands:
+      - name: stg
+servers:
+  conoha:
+    origin:
+      identity: https://example.com
+`
+	file := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := checkUnknownKeys(file, reflect.TypeOf(Config{})); err != nil {
+		t.Errorf("checkUnknownKeys() error = %v", err)
+	}
+}
+
+func TestSchema(t *testing.T) {
+	got, err := Schema()
+	if err != nil {
+		t.Fatalf("Schema() error = %v", err)
+	}
+	published, err := os.ReadFile("../../config.schema.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != string(published) {
+		t.Errorf("config.schema.json is outdated, regenerate it with `DevOpsBot config schema > config.schema.json`")
+	}
+}
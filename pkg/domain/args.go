@@ -0,0 +1,81 @@
+package domain
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Arg describes a declared command argument.
+type Arg struct {
+	// Name is the argument name, displayed in help messages and forms.
+	Name string
+	// Description optionally describes the argument.
+	Description string
+	// Required is true if the argument must be supplied.
+	Required bool
+	// Enum is an optional list of allowed values.
+	Enum []string
+	// Pattern is an optional regular expression which the value must fully match.
+	Pattern *regexp.Regexp
+}
+
+// Validate checks the value against the declaration.
+func (a *Arg) Validate(value string) error {
+	if len(a.Enum) > 0 && !slices.Contains(a.Enum, value) {
+		return fmt.Errorf("argument `%s` must be one of %s (got `%s`)", a.Name, strings.Join(a.Enum, ", "), value)
+	}
+	if a.Pattern != nil && !a.Pattern.MatchString(value) {
+		return fmt.Errorf("argument `%s` must match `%s` (got `%s`)", a.Name, a.Pattern.String(), value)
+	}
+	return nil
+}
+
+// Syntax returns the display syntax of the argument. (example: "<env:stg|prod>", "[tag]")
+func (a *Arg) Syntax() string {
+	s := a.Name
+	if len(a.Enum) > 0 {
+		s += ":" + strings.Join(a.Enum, "|")
+	}
+	if a.Required {
+		return "<" + s + ">"
+	}
+	return "[" + s + "]"
+}
+
+// ArgsSyntax returns the display syntax of the declared arguments.
+func ArgsSyntax(decls []*Arg) string {
+	syntaxes := make([]string, 0, len(decls))
+	for _, a := range decls {
+		syntaxes = append(syntaxes, a.Syntax())
+	}
+	return strings.Join(syntaxes, " ")
+}
+
+// RequiredArgs returns the number of required arguments.
+func RequiredArgs(decls []*Arg) int {
+	n := 0
+	for _, a := range decls {
+		if a.Required {
+			n++
+		}
+	}
+	return n
+}
+
+// ValidateArgs checks the positional arguments against the declarations.
+func ValidateArgs(decls []*Arg, args []string) error {
+	if required := RequiredArgs(decls); len(args) < required {
+		return fmt.Errorf("missing required argument `%s`", decls[len(args)].Name)
+	}
+	if len(args) > len(decls) {
+		return fmt.Errorf("too many arguments (expected at most %d, got %d)", len(decls), len(args))
+	}
+	for i, arg := range args {
+		if err := decls[i].Validate(arg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -2,14 +2,23 @@ package domain
 
 import (
 	"context"
+	"slices"
+
 	"go.uber.org/zap"
 )
 
 type Bot interface {
-	// Start connects the bot. Must block on success.
+	// Start connects the bot. Must block on success, until ctx is cancelled.
 	Start(ctx context.Context) error
 }
 
+// HealthReporter is optionally implemented by Bot,
+// to report its connection state to health checks.
+type HealthReporter interface {
+	// Healthy returns non-nil error if the bot cannot receive commands at the moment.
+	Healthy() error
+}
+
 type StampNames struct {
 	BadCommand string
 	Forbid     string
@@ -18,16 +27,52 @@ type StampNames struct {
 	Running    string
 }
 
+// Channel describes the channel in which a command was sent.
+type Channel struct {
+	// ID is the platform-specific channel ID.
+	ID string
+	// Prefix is the bot command prefix in this channel.
+	Prefix string
+	// Commands is the list of top-level command names available in this channel.
+	// If empty, all commands are available.
+	Commands []string
+	// DM is true if this is a direct message channel with the bot.
+	DM bool
+}
+
+// Allows reports whether the top-level command is available in this channel.
+func (c *Channel) Allows(command string) bool {
+	return len(c.Commands) == 0 || slices.Contains(c.Commands, command)
+}
+
+// ReplyOptions controls how replies are posted.
+type ReplyOptions struct {
+	// Thread posts replies in the thread of the command message.
+	Thread bool
+	// Broadcast additionally posts the final status reply to the channel, when Thread is set.
+	Broadcast bool
+}
+
 // Context コマンド実行コンテキスト
 type Context interface {
 	context.Context
 
+	// Platform returns the name of the chat platform the command was sent from. (example: "traq", "slack")
+	Platform() string
+	// Channel returns the channel in which the command was sent.
+	Channel() *Channel
 	// Executor コマンドを実行した人 (メッセージの投稿者のID) を返します
 	Executor() string
 	// Args 投稿メッセージを空白区切りで分けたもの
 	Args() []string
 	// ShiftArgs pops the first argument and creates a new command context.
 	ShiftArgs() Context
+	// WithArgs creates a new command context with the arguments replaced, such as by expanding aliases.
+	WithArgs(args []string) Context
+	// ReplyOptions returns the current reply options.
+	ReplyOptions() ReplyOptions
+	// WithReplyOptions creates a new command context with the given reply options.
+	WithReplyOptions(opts ReplyOptions) Context
 
 	// L returns logger.
 	L() *zap.Logger
@@ -37,15 +82,57 @@ type Context interface {
 	StampNames() *StampNames
 
 	// ReplyBad コマンドメッセージにBadスタンプをつけて返信します
-	ReplyBad(message ...string) error
+	ReplyBad(message ...Block) error
 	// ReplyForbid コマンドメッセージにForbidスタンプをつけて返信します
-	ReplyForbid(message ...string) error
+	ReplyForbid(message ...Block) error
 	// ReplySuccess コマンドメッセージにSuccessスタンプをつけて返信します
-	ReplySuccess(message ...string) error
+	ReplySuccess(message ...Block) error
 	// ReplyFailure コマンドメッセージにFailureスタンプをつけて返信します
-	ReplyFailure(message ...string) error
+	ReplyFailure(message ...Block) error
 	// ReplyRunning コマンドメッセージにRunningスタンプをつけて返信します
-	ReplyRunning(message ...string) error
+	ReplyRunning(message ...Block) error
+}
+
+// ExitCodeRecorder is optionally implemented by Context,
+// to display the exit code of the executed command in the following replies.
+type ExitCodeRecorder interface {
+	RecordExitCode(code int)
+}
+
+// CommandPathRecorder is optionally implemented by Context,
+// to record the path of the command resolved from the arguments, such as by expanding aliases or prefixes.
+type CommandPathRecorder interface {
+	RecordCommandPath(path []string)
+}
+
+// CommandLister is optionally implemented by the root Command,
+// to list all commands regardless of operators, for generating platform configurations.
+type CommandLister interface {
+	ListAll() []*CommandInfo
+}
+
+// CommandInfo describes a compiled command.
+type CommandInfo struct {
+	// Path is the list of verbs to reach this command from the root. (example: ["deploy", "stg"])
+	Path []string
+	// Description describes what this command does in one line.
+	Description string
+	// LongDescription optionally documents this command in detail.
+	LongDescription string
+	// Examples are usage examples, written as the arguments following this command.
+	Examples []string
+	// AllowArgs is true if this command accepts extra arguments.
+	AllowArgs bool
+	// ArgsSyntax is an optional arguments syntax to display.
+	ArgsSyntax string
+	// Args are the declared arguments, if any.
+	Args []*Arg
+	// Operators are the effective operators, including the inherited ones. Empty if everyone is allowed.
+	Operators []string
+	// AllowDM is true if this command can be executed in direct messages.
+	AllowDM bool
+	// Runnable is true if this command can be executed by itself, not only via sub-commands.
+	Runnable bool
 }
 
 // Command コマンドインターフェース
@@ -53,5 +140,9 @@ type Command interface {
 	Execute(ctx Context) error
 	HasSubcommands() bool
 	GetSubcommand(verb string) (Command, bool)
-	HelpMessage(indent int, formatSub bool) []string
+	// HelpMessage returns the usage of this command, and sub-commands nested if formatSub is set.
+	HelpMessage(ctx Context, formatSub bool) []*ListItem
+	// List returns this command (if any) and all sub-commands which the executor is allowed to execute,
+	// sorted by path.
+	List(executor string) []*CommandInfo
 }
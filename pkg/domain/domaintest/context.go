@@ -0,0 +1,248 @@
+// Package domaintest provides utilities for testing commands without connecting to any chat platform.
+package domaintest
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"go.uber.org/zap"
+
+	"github.com/traPtitech/DevOpsBot/pkg/domain"
+)
+
+var (
+	_ domain.Context             = (*Context)(nil)
+	_ domain.CommandPathRecorder = (*Context)(nil)
+)
+
+// ReplyKind is the kind of reply, corresponding to each Reply* method of domain.Context.
+type ReplyKind string
+
+const (
+	ReplyBad     ReplyKind = "bad"
+	ReplyForbid  ReplyKind = "forbid"
+	ReplySuccess ReplyKind = "success"
+	ReplyFailure ReplyKind = "failure"
+	ReplyRunning ReplyKind = "running"
+)
+
+// Reply is a recorded reply.
+type Reply struct {
+	Kind ReplyKind
+	// Stamp is the stamp name which would have been pushed to the command message.
+	Stamp string
+	// Blocks is the reply message, if any.
+	Blocks []domain.Block
+	// Message is the reply message rendered in markdown as on traQ, split into lines.
+	Message []string
+	// Args is the remaining arguments of the context at the time of reply.
+	Args []string
+	// Options is the reply options of the context at the time of reply.
+	Options domain.ReplyOptions
+}
+
+// recorder is shared by all contexts derived from the same NewContext call.
+type recorder struct {
+	mu          sync.Mutex
+	replies     []Reply
+	commandPath []string
+}
+
+// Context is an in-memory domain.Context which records all replies.
+type Context struct {
+	context.Context
+
+	platform   string
+	channel    *domain.Channel
+	executor   string
+	args       []string
+	opts       domain.ReplyOptions
+	limit      int
+	stampNames *domain.StampNames
+	logger     *zap.Logger
+
+	rec *recorder
+}
+
+// NewContext creates a new recording context executed by the given executor with the given arguments.
+func NewContext(executor string, args ...string) *Context {
+	return &Context{
+		Context:  context.Background(),
+		platform: "traq",
+		channel:  &domain.Channel{ID: "test", Prefix: "/"},
+		executor: executor,
+		args:     args,
+		limit:    9900,
+		stampNames: &domain.StampNames{
+			BadCommand: string(ReplyBad),
+			Forbid:     string(ReplyForbid),
+			Success:    string(ReplySuccess),
+			Failure:    string(ReplyFailure),
+			Running:    string(ReplyRunning),
+		},
+		logger: zap.NewNop(),
+		rec:    &recorder{},
+	}
+}
+
+// WithMessageLimit sets the reply character limit returned by MessageLimit.
+func (ctx *Context) WithMessageLimit(limit int) *Context {
+	ctx.limit = limit
+	return ctx
+}
+
+// WithPlatform sets the platform name returned by Platform. Defaults to "traq".
+func (ctx *Context) WithPlatform(platform string) *Context {
+	ctx.platform = platform
+	return ctx
+}
+
+// WithChannel sets the channel returned by Channel. Defaults to a channel allowing all commands with "/" prefix.
+func (ctx *Context) WithChannel(channel *domain.Channel) *Context {
+	ctx.channel = channel
+	return ctx
+}
+
+// Replies returns all replies recorded so far, in order.
+func (ctx *Context) Replies() []Reply {
+	ctx.rec.mu.Lock()
+	defer ctx.rec.mu.Unlock()
+	replies := make([]Reply, len(ctx.rec.replies))
+	copy(replies, ctx.rec.replies)
+	return replies
+}
+
+// RepliesOf returns recorded replies of the given kind, in order.
+func (ctx *Context) RepliesOf(kind ReplyKind) []Reply {
+	var replies []Reply
+	for _, r := range ctx.Replies() {
+		if r.Kind == kind {
+			replies = append(replies, r)
+		}
+	}
+	return replies
+}
+
+// Stamps returns names of the stamps pushed so far, in order.
+func (ctx *Context) Stamps() []string {
+	var stamps []string
+	for _, r := range ctx.Replies() {
+		stamps = append(stamps, r.Stamp)
+	}
+	return stamps
+}
+
+// CommandPath returns the path of the resolved command recorded by RecordCommandPath, if any.
+func (ctx *Context) CommandPath() []string {
+	ctx.rec.mu.Lock()
+	defer ctx.rec.mu.Unlock()
+	return ctx.rec.commandPath
+}
+
+func (ctx *Context) RecordCommandPath(path []string) {
+	ctx.rec.mu.Lock()
+	defer ctx.rec.mu.Unlock()
+	ctx.rec.commandPath = path
+}
+
+// Last returns the last recorded reply.
+func (ctx *Context) Last() (Reply, bool) {
+	replies := ctx.Replies()
+	if len(replies) == 0 {
+		return Reply{}, false
+	}
+	return replies[len(replies)-1], true
+}
+
+func (ctx *Context) Platform() string {
+	return ctx.platform
+}
+
+func (ctx *Context) Channel() *domain.Channel {
+	return ctx.channel
+}
+
+func (ctx *Context) Executor() string {
+	return ctx.executor
+}
+
+func (ctx *Context) Args() []string {
+	return ctx.args
+}
+
+func (ctx *Context) ShiftArgs() domain.Context {
+	newCtx := *ctx
+	newCtx.args = newCtx.args[1:]
+	return &newCtx
+}
+
+func (ctx *Context) WithArgs(args []string) domain.Context {
+	newCtx := *ctx
+	newCtx.args = args
+	return &newCtx
+}
+
+func (ctx *Context) ReplyOptions() domain.ReplyOptions {
+	return ctx.opts
+}
+
+func (ctx *Context) WithReplyOptions(opts domain.ReplyOptions) domain.Context {
+	newCtx := *ctx
+	newCtx.opts = opts
+	return &newCtx
+}
+
+func (ctx *Context) L() *zap.Logger {
+	return ctx.logger
+}
+
+func (ctx *Context) MessageLimit() int {
+	return ctx.limit
+}
+
+func (ctx *Context) StampNames() *domain.StampNames {
+	return ctx.stampNames
+}
+
+func (ctx *Context) record(kind ReplyKind, stamp string, message []domain.Block) error {
+	var lines []string
+	if len(message) > 0 {
+		lines = strings.Split(domain.Markdown(message, &domain.MarkdownStyle{
+			Stamps:  ctx.stampNames,
+			Mention: func(name string) string { return ":@" + name + ":" },
+		}), "\n")
+	}
+
+	ctx.rec.mu.Lock()
+	defer ctx.rec.mu.Unlock()
+	ctx.rec.replies = append(ctx.rec.replies, Reply{
+		Kind:    kind,
+		Stamp:   stamp,
+		Blocks:  message,
+		Message: lines,
+		Args:    ctx.args,
+		Options: ctx.opts,
+	})
+	return nil
+}
+
+func (ctx *Context) ReplyBad(message ...domain.Block) error {
+	return ctx.record(ReplyBad, ctx.stampNames.BadCommand, message)
+}
+
+func (ctx *Context) ReplyForbid(message ...domain.Block) error {
+	return ctx.record(ReplyForbid, ctx.stampNames.Forbid, message)
+}
+
+func (ctx *Context) ReplySuccess(message ...domain.Block) error {
+	return ctx.record(ReplySuccess, ctx.stampNames.Success, message)
+}
+
+func (ctx *Context) ReplyFailure(message ...domain.Block) error {
+	return ctx.record(ReplyFailure, ctx.stampNames.Failure, message)
+}
+
+func (ctx *Context) ReplyRunning(message ...domain.Block) error {
+	return ctx.record(ReplyRunning, ctx.stampNames.Running, message)
+}
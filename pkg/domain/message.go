@@ -0,0 +1,295 @@
+package domain
+
+import (
+	"fmt"
+	"html"
+	"slices"
+	"strings"
+)
+
+// Block is a block-level element of reply messages, rendered natively by each platform.
+//
+// Available blocks are Heading, Paragraph, CodeBlock, Fields, and List.
+type Block interface {
+	isBlock()
+}
+
+// Inline is an inline element of reply messages.
+//
+// Available inline elements are Text, Code, Mention, and Status.
+type Inline interface {
+	isInline()
+}
+
+// Heading is a section heading.
+type Heading []Inline
+
+// Paragraph is a paragraph of inline elements.
+type Paragraph []Inline
+
+// CodeBlock is a preformatted text, such as command outputs.
+type CodeBlock string
+
+// Fields is a list of key/value pairs.
+type Fields []Field
+
+// Field is a key/value pair.
+type Field struct {
+	Name  string
+	Value []Inline
+}
+
+// List is a bulleted list.
+type List []*ListItem
+
+// ListItem is an item of bulleted list, which may have nested items.
+type ListItem struct {
+	Content  []Inline
+	Children []*ListItem
+}
+
+func (Heading) isBlock()   {}
+func (Paragraph) isBlock() {}
+func (CodeBlock) isBlock() {}
+func (Fields) isBlock()    {}
+func (List) isBlock()      {}
+
+// Text is a plain text.
+type Text string
+
+// Code is an inline code. (example: command names)
+type Code string
+
+// Mention refers to a user by the operator name.
+type Mention string
+
+// Status is the icon of command status, which is the same stamp or emoji as the reactions.
+type Status StatusKind
+
+// StatusKind is the kind of command status, corresponding to each Reply* method of Context.
+type StatusKind int
+
+const (
+	StatusBadCommand StatusKind = iota
+	StatusForbid
+	StatusSuccess
+	StatusFailure
+	StatusRunning
+)
+
+func (Text) isInline()    {}
+func (Code) isInline()    {}
+func (Mention) isInline() {}
+func (Status) isInline()  {}
+
+// Textf returns a paragraph of formatted plain text.
+func Textf(format string, a ...any) Paragraph {
+	return Paragraph{Text(fmt.Sprintf(format, a...))}
+}
+
+// Name returns the stamp name of the status.
+func (s *StampNames) Name(kind StatusKind) string {
+	switch kind {
+	case StatusBadCommand:
+		return s.BadCommand
+	case StatusForbid:
+		return s.Forbid
+	case StatusSuccess:
+		return s.Success
+	case StatusFailure:
+		return s.Failure
+	case StatusRunning:
+		return s.Running
+	default:
+		return ""
+	}
+}
+
+// MarkdownStyle describes platform-specific formatting of the inline elements in markdown.
+type MarkdownStyle struct {
+	// Stamps are the stamp names to display statuses as ":name:".
+	Stamps *StampNames
+	// Mention optionally formats the mention. Defaults to the operator name as is.
+	Mention func(name string) string
+	// Status optionally formats the stamp name of the status. Defaults to ":name:".
+	Status func(name string) string
+}
+
+// Markdown renders the blocks in markdown, which traQ, Mattermost, Discord, and Matrix clients understand.
+func Markdown(blocks []Block, style *MarkdownStyle) string {
+	parts := make([]string, 0, len(blocks))
+	for _, b := range blocks {
+		switch b := b.(type) {
+		case Heading:
+			parts = append(parts, "## "+style.Inlines(b))
+		case Paragraph:
+			parts = append(parts, style.Inlines(b))
+		case CodeBlock:
+			parts = append(parts, "```\n"+strings.TrimSuffix(string(b), "\n")+"\n```")
+		case Fields:
+			lines := make([]string, 0, len(b))
+			for _, f := range b {
+				lines = append(lines, "**"+f.Name+"**: "+style.Inlines(f.Value))
+			}
+			parts = append(parts, strings.Join(lines, "\n"))
+		case List:
+			parts = append(parts, strings.Join(ListLines(b, "- ", style.Inlines), "\n"))
+		}
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// Inlines renders the inline elements in markdown.
+func (style *MarkdownStyle) Inlines(inlines []Inline) string {
+	var sb strings.Builder
+	for _, in := range inlines {
+		switch in := in.(type) {
+		case Text:
+			sb.WriteString(string(in))
+		case Code:
+			sb.WriteString("`" + string(in) + "`")
+		case Mention:
+			if style.Mention != nil {
+				sb.WriteString(style.Mention(string(in)))
+			} else {
+				sb.WriteString(string(in))
+			}
+		case Status:
+			name := style.Stamps.Name(StatusKind(in))
+			switch {
+			case name == "":
+			case style.Status != nil:
+				sb.WriteString(style.Status(name))
+			default:
+				sb.WriteString(":" + name + ":")
+			}
+		}
+	}
+	return sb.String()
+}
+
+// PlainText renders the blocks in plain text, for terminals.
+func PlainText(blocks []Block) string {
+	parts := make([]string, 0, len(blocks))
+	for _, b := range blocks {
+		switch b := b.(type) {
+		case Heading:
+			parts = append(parts, strings.TrimSpace(PlainInlines(b)))
+		case Paragraph:
+			parts = append(parts, strings.TrimSpace(PlainInlines(b))) // Statuses may leave spaces
+		case CodeBlock:
+			parts = append(parts, strings.TrimSuffix(string(b), "\n"))
+		case Fields:
+			lines := make([]string, 0, len(b))
+			for _, f := range b {
+				lines = append(lines, f.Name+": "+PlainInlines(f.Value))
+			}
+			parts = append(parts, strings.Join(lines, "\n"))
+		case List:
+			parts = append(parts, strings.Join(ListLines(b, "- ", PlainInlines), "\n"))
+		}
+	}
+	// Skip blocks rendered empty, such as a paragraph of a status only
+	parts = slices.DeleteFunc(parts, func(part string) bool { return part == "" })
+	return strings.Join(parts, "\n\n")
+}
+
+// PlainInlines renders the inline elements in plain text. Statuses are omitted.
+func PlainInlines(inlines []Inline) string {
+	var sb strings.Builder
+	for _, in := range inlines {
+		switch in := in.(type) {
+		case Text:
+			sb.WriteString(string(in))
+		case Code:
+			sb.WriteString(string(in))
+		case Mention:
+			sb.WriteString(string(in))
+		}
+	}
+	return sb.String()
+}
+
+// HTML renders the blocks in HTML fragments, such as for static documentation. Statuses are omitted.
+func HTML(blocks []Block) string {
+	return HTMLWithStamps(blocks, nil)
+}
+
+// HTMLWithStamps renders the blocks in HTML fragments, displaying statuses as the stamps as is,
+// such as Unicode emojis in Matrix messages. Statuses are omitted if stamps is nil.
+func HTMLWithStamps(blocks []Block, stamps *StampNames) string {
+	parts := make([]string, 0, len(blocks))
+	for _, b := range blocks {
+		switch b := b.(type) {
+		case Heading:
+			parts = append(parts, "<h2>"+htmlInlines(b, stamps)+"</h2>")
+		case Paragraph:
+			parts = append(parts, "<p>"+strings.ReplaceAll(strings.TrimSpace(htmlInlines(b, stamps)), "\n", "<br>\n")+"</p>")
+		case CodeBlock:
+			parts = append(parts, "<pre><code>"+html.EscapeString(strings.TrimSuffix(string(b), "\n"))+"</code></pre>")
+		case Fields:
+			var sb strings.Builder
+			sb.WriteString("<dl>\n")
+			for _, f := range b {
+				sb.WriteString("<dt>" + html.EscapeString(f.Name) + "</dt><dd>" + htmlInlines(f.Value, stamps) + "</dd>\n")
+			}
+			sb.WriteString("</dl>")
+			parts = append(parts, sb.String())
+		case List:
+			parts = append(parts, htmlList(b, stamps))
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+// HTMLInlines renders the inline elements in HTML. Statuses are omitted.
+func HTMLInlines(inlines []Inline) string {
+	return htmlInlines(inlines, nil)
+}
+
+func htmlInlines(inlines []Inline, stamps *StampNames) string {
+	var sb strings.Builder
+	for _, in := range inlines {
+		switch in := in.(type) {
+		case Text:
+			sb.WriteString(html.EscapeString(string(in)))
+		case Code:
+			sb.WriteString("<code>" + html.EscapeString(string(in)) + "</code>")
+		case Mention:
+			sb.WriteString(html.EscapeString(string(in)))
+		case Status:
+			if stamps != nil {
+				sb.WriteString(html.EscapeString(stamps.Name(StatusKind(in))))
+			}
+		}
+	}
+	return sb.String()
+}
+
+func htmlList(items List, stamps *StampNames) string {
+	var sb strings.Builder
+	sb.WriteString("<ul>\n")
+	for _, item := range items {
+		sb.WriteString("<li>" + htmlInlines(item.Content, stamps))
+		if len(item.Children) > 0 {
+			sb.WriteString("\n" + htmlList(item.Children, stamps) + "\n")
+		}
+		sb.WriteString("</li>\n")
+	}
+	sb.WriteString("</ul>")
+	return sb.String()
+}
+
+// ListLines renders the list items with the bullet, indenting nested items by 2 spaces.
+func ListLines(items List, bullet string, inlines func([]Inline) string) []string {
+	return listLines(items, 0, bullet, inlines)
+}
+
+func listLines(items List, indent int, bullet string, inlines func([]Inline) string) []string {
+	var lines []string
+	for _, item := range items {
+		lines = append(lines, strings.Repeat(" ", indent)+bullet+inlines(item.Content))
+		lines = append(lines, listLines(item.Children, indent+2, bullet, inlines)...)
+	}
+	return lines
+}
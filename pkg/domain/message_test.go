@@ -0,0 +1,71 @@
+package domain
+
+import "testing"
+
+func TestMarkdown(t *testing.T) {
+	message := []Block{
+		Heading{Code("/deploy"), Text(" Usage")},
+		List{{
+			Content:  []Inline{Code("/deploy <tag>"), Text(" ("), Mention("alice"), Text(", "), Mention("bob"), Text(")")},
+			Children: []*ListItem{{Content: []Inline{Code("/deploy stg")}}},
+		}},
+		Fields{{Name: "Exit code", Value: []Inline{Text("1")}}},
+		Paragraph{Status(StatusFailure), Text(" exec failed")},
+		CodeBlock("output\n"),
+	}
+	style := &MarkdownStyle{
+		Stamps:  &StampNames{Failure: "x"},
+		Mention: func(name string) string { return "@" + name },
+	}
+
+	want := "## `/deploy` Usage\n\n" +
+		"- `/deploy <tag>` (@alice, @bob)\n  - `/deploy stg`\n\n" +
+		"**Exit code**: 1\n\n" +
+		":x: exec failed\n\n" +
+		"```\noutput\n```"
+	if got := Markdown(message, style); got != want {
+		t.Errorf("Markdown() =\n%s\nwant\n%s", got, want)
+	}
+
+	wantPlain := "/deploy Usage\n\n" +
+		"- /deploy <tag> (alice, bob)\n  - /deploy stg\n\n" +
+		"Exit code: 1\n\n" +
+		"exec failed\n\n" +
+		"output"
+	if got := PlainText(message); got != wantPlain {
+		t.Errorf("PlainText() =\n%s\nwant\n%s", got, wantPlain)
+	}
+
+	// A status only paragraph does not leave blank lines
+	if got := PlainText([]Block{Paragraph{Status(StatusSuccess)}, CodeBlock("output\n")}); got != "output" {
+		t.Errorf("PlainText() = %q, want %q", got, "output")
+	}
+}
+
+func TestHTML(t *testing.T) {
+	message := []Block{
+		Heading{Code("/deploy"), Text(" Usage")},
+		List{{
+			Content:  []Inline{Code("/deploy <tag>"), Text(" ("), Mention("alice"), Text(")")},
+			Children: []*ListItem{{Content: []Inline{Code("/deploy stg")}}},
+		}},
+		Fields{{Name: "Exit code", Value: []Inline{Text("1")}}},
+		Paragraph{Status(StatusFailure), Text(" exec failed\nretry")},
+		CodeBlock("a < b\n"),
+	}
+
+	want := "<h2><code>/deploy</code> Usage</h2>\n" +
+		"<ul>\n<li><code>/deploy &lt;tag&gt;</code> (alice)\n<ul>\n<li><code>/deploy stg</code></li>\n</ul>\n</li>\n</ul>\n" +
+		"<dl>\n<dt>Exit code</dt><dd>1</dd>\n</dl>\n" +
+		"<p>exec failed<br>\nretry</p>\n" +
+		"<pre><code>a &lt; b</code></pre>"
+	if got := HTML(message); got != want {
+		t.Errorf("HTML() =\n%s\nwant\n%s", got, want)
+	}
+
+	stamps := &StampNames{Failure: "❌"}
+	wantStatus := "<p>❌ exec failed</p>"
+	if got := HTMLWithStamps([]Block{Paragraph{Status(StatusFailure), Text(" exec failed")}}, stamps); got != wantStatus {
+		t.Errorf("HTMLWithStamps() = %s, want %s", got, wantStatus)
+	}
+}